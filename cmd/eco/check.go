@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	eco "github.com/orkungursel/go-eco"
+)
+
+// stringsFlag is a flag which can be repeated.
+type stringsFlag []string
+
+// String implements the flag.Value interface.
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set implements the flag.Value interface.
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// runCheck runs the "check" command.
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var sf structFlags
+	sf.register(fs)
+
+	var envFiles stringsFlag
+	fs.Var(&envFiles, "env-file", "dotenv `file` to check, can be repeated (default the environment of the process)")
+
+	dir, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+
+	src := eco.EnvSource()
+	if len(envFiles) > 0 {
+		if src, err = eco.DotenvSource(envFiles...); err != nil {
+			fmt.Fprintf(stderr, "eco: %v\n", err)
+			return 1
+		}
+	}
+
+	cs, e, err := sf.load(dir, src)
+	if err != nil {
+		fmt.Fprintf(stderr, "eco: %v\n", err)
+		return 1
+	}
+
+	problems := check(cs, e)
+	for _, p := range problems {
+		fmt.Fprintln(stdout, p)
+	}
+
+	if len(problems) > 0 {
+		fmt.Fprintf(stderr, "eco: %d problem(s) found in %s\n", len(problems), src.Name())
+		return 1
+	}
+
+	return 0
+}
+
+// check unmarshals the variables into the config struct and returns the
+// problems: the missing required variables, the values which cannot be
+// converted, the recursive fields and, if a prefix is given, the prefixed
+// variables which are not read by any field.
+func check(cs *configStruct, e instance) []string {
+	err := e.Unmarshal(cs.New())
+	if err == nil {
+		return nil
+	}
+
+	var merr *eco.MultiError
+	if !errors.As(err, &merr) {
+		return []string{err.Error()}
+	}
+
+	problems := make([]string, 0, len(merr.Errors))
+	for _, fe := range merr.Errors {
+		if t, ok := cs.Cycle(fe.Field); ok && errors.Is(fe.Err, eco.ErrTypeCycle) {
+			fe.Err = fmt.Errorf("%w: %s", eco.ErrTypeCycle, t)
+		}
+
+		if fe.Field != "" {
+			fe.Field = cs.FieldPath(fe.Field)
+		}
+		problems = append(problems, fe.Error())
+	}
+
+	return problems
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		envs     map[string]string
+		want     string
+		wantErr  string
+		wantCode int
+	}{
+		{
+			name: "should pass if the environment is valid",
+			args: []string{"check", "-type", "Config", "./testdata/secret"},
+		},
+		{
+			name:     "should report the recursive fields",
+			args:     []string{"check", "-type", "Config", "-prefix", "APP", "./testdata/config", "--env-file", "testdata/ok.env"},
+			want:     "APP_TREE_NEXT (Config.Tree.Next): recursive struct type: config.Node\n",
+			wantErr:  "1 problem(s) found in testdata/ok.env",
+			wantCode: 1,
+		},
+		{
+			name: "should report all the problems",
+			args: []string{"check", "-type", "Config", "-prefix", "APP", "./testdata/config", "--env-file", "testdata/bad.env"},
+			want: `APP_HOST (Config.Host): required environment variable is not set
+APP_PORT (Config.Port): strconv.Atoi: parsing "abc": invalid syntax
+APP_DEBUG (Config.Debug): strconv.ParseBool: parsing "maybe": invalid syntax
+APP_TIMEOUT (Config.Timeout): time: missing unit in duration "5"
+APP_DB_URL (Config.DB.URL): required environment variable is not set
+APP_TREE_NEXT (Config.Tree.Next): recursive struct type: config.Node
+APP_PROT: unknown environment variable, did you mean APP_PORT?
+APP_UPSTREAMS_2_HOST: unknown environment variable, did you mean APP_UPSTREAMS_0_HOST?
+`,
+			wantErr:  "8 problem(s) found in testdata/bad.env",
+			wantCode: 1,
+		},
+		{
+			name: "should not report the unknown variables without a prefix",
+			args: []string{"check", "-type", "Config", "./testdata/config", "--env-file", "testdata/ok.env"},
+			want: `HOST (Config.Host): required environment variable is not set
+DB_URL (Config.DB.URL): required environment variable is not set
+TREE_NEXT (Config.Tree.Next): recursive struct type: config.Node
+`,
+			wantCode: 1,
+		},
+		{
+			name:     "should check the environment of the process",
+			args:     []string{"check", "-type", "Config", "-prefix", "APP", "./testdata/config"},
+			envs:     map[string]string{"APP_HOST": "localhost", "APP_DB_URL": "postgres://db/app", "APP_PROT": "8080"},
+			want:     "APP_TREE_NEXT (Config.Tree.Next): recursive struct type: config.Node\nAPP_PROT: unknown environment variable, did you mean APP_PORT?\n",
+			wantCode: 1,
+		},
+		{
+			name: "should read the variables of the slices of recursive types",
+			args: []string{"check", "-type", "Node", "-prefix", "APP", "./testdata/tree"},
+			envs: map[string]string{
+				"APP_NAME":                       "root",
+				"APP_CHILDREN_0_NAME":            "a",
+				"APP_CHILDREN_0_CHILDREN_0_NAME": "b",
+				"APP_LINKS_0_NAME":               "c",
+			},
+		},
+		{
+			name:     "should report the unknown variables next to the slices of recursive types",
+			args:     []string{"check", "-type", "Node", "-prefix", "APP", "./testdata/tree"},
+			envs:     map[string]string{"APP_CHILDREN_0_NAME": "a", "APP_CHILD_0_NAME": "b"},
+			want:     "APP_CHILD_0_NAME: unknown environment variable\n",
+			wantCode: 1,
+		},
+		{
+			name:     "should redact the secret values",
+			args:     []string{"check", "-type", "Config", "./testdata/secret"},
+			envs:     map[string]string{"PIN": "12ab"},
+			want:     "PIN (Config.Pin): invalid value\n",
+			wantCode: 1,
+		},
+		{
+			name:     "should fail if the dotenv file cannot be read",
+			args:     []string{"check", "-type", "Config", "./testdata/config", "--env-file", "testdata/missing.env"},
+			wantErr:  "no such file or directory",
+			wantCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Fatalf("run() = %d, want %d, stderr: %s", code, tt.wantCode, stderr.String())
+			}
+
+			if !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.wantErr)
+			}
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run() stdout = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	eco "github.com/orkungursel/go-eco"
+)
+
+// indexPlaceholder is the path part which eco uses for
+// the indices of the slices of structs.
+const indexPlaceholder = "[{N}]"
+
+var (
+	anyType      = reflect.TypeOf((*interface{})(nil)).Elem()
+	cycleType    = reflect.TypeOf(cycleValue{})
+	emptyType    = reflect.TypeOf(struct{}{})
+	prefixType   = reflect.TypeOf(map[string]string(nil))
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	secretType   = reflect.TypeOf(eco.Secret(""))
+	textType     = reflect.TypeOf(textValue(""))
+)
+
+// basicTypes maps the basic Go types to their reflect types.
+var basicTypes = map[types.BasicKind]reflect.Type{
+	types.Bool:       reflect.TypeOf(false),
+	types.Int:        reflect.TypeOf(int(0)),
+	types.Int8:       reflect.TypeOf(int8(0)),
+	types.Int16:      reflect.TypeOf(int16(0)),
+	types.Int32:      reflect.TypeOf(int32(0)),
+	types.Int64:      reflect.TypeOf(int64(0)),
+	types.Uint:       reflect.TypeOf(uint(0)),
+	types.Uint8:      reflect.TypeOf(uint8(0)),
+	types.Uint16:     reflect.TypeOf(uint16(0)),
+	types.Uint32:     reflect.TypeOf(uint32(0)),
+	types.Uint64:     reflect.TypeOf(uint64(0)),
+	types.Uintptr:    reflect.TypeOf(uintptr(0)),
+	types.Float32:    reflect.TypeOf(float32(0)),
+	types.Float64:    reflect.TypeOf(float64(0)),
+	types.Complex64:  reflect.TypeOf(complex64(0)),
+	types.Complex128: reflect.TypeOf(complex128(0)),
+	types.String:     reflect.TypeOf(""),
+}
+
+// textValue stands for the types which decode themselves, e.g. with
+// encoding.TextUnmarshaler, since their methods cannot be run statically.
+// It accepts any value.
+type textValue string
+
+var _ encoding.TextUnmarshaler = (*textValue)(nil)
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *textValue) UnmarshalText(text []byte) error {
+	*v = textValue(text)
+	return nil
+}
+
+// indexPattern matches the indices of the slices of structs in the
+// field paths of the errors, e.g. "[0]" in "Upstreams[0].Host".
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// cycleValue stands for the pointers to the recursive struct types, e.g.
+// Next *Node in Node, which eco fails to bind with eco.ErrTypeCycle. It has
+// no fields, so that Describe skips it like the recursive fields, and it
+// reports the cycle when eco binds it.
+type cycleValue struct{}
+
+var _ eco.Validator = cycleValue{}
+
+// Validate implements eco.Validator.
+func (cycleValue) Validate() error {
+	return eco.ErrTypeCycle
+}
+
+// configStruct is a config struct which is found in a Go package.
+type configStruct struct {
+	// Name is the name of the struct type.
+	Name string
+	// Type is a reflect type which has the same fields
+	// and tags as the struct, so that it can be used with eco.
+	Type reflect.Type
+	// FieldTypes maps the Go paths of the fields, relative to the
+	// struct, to the names of their Go types.
+	FieldTypes map[string]string
+	// Cycles maps the Go paths of the recursive fields, relative to the
+	// struct, to the names of the struct types which they refer to.
+	Cycles map[string]string
+	// Recursive is the set of the Go paths of the slices of the recursive
+	// struct types, relative to the struct, which are not described.
+	Recursive map[string]bool
+}
+
+// New returns a pointer to a new value of the struct.
+func (c *configStruct) New() interface{} {
+	return reflect.New(c.Type).Interface()
+}
+
+// FieldPath returns the Go path of the given field path,
+// which is relative to the struct, including the struct name.
+func (c *configStruct) FieldPath(path string) string {
+	if path == "" {
+		return c.Name
+	}
+	return c.Name + "." + path
+}
+
+// Cycle returns the name of the struct type which the recursive field
+// with the given path refers to, if the field is recursive. The indices
+// of the slices of structs in the path are ignored.
+func (c *configStruct) Cycle(path string) (string, bool) {
+	t, ok := c.Cycles[indexPattern.ReplaceAllLiteralString(path, indexPlaceholder)]
+	return t, ok
+}
+
+// loadStruct parses and type checks the Go package in the given directory,
+// and returns the struct type with the given name. The type errors, e.g.
+// missing dependencies, are ignored as long as the struct can be found.
+func loadStruct(dir, name string) (*configStruct, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+
+	for pkgName, pkg := range pkgs {
+		files := make([]*ast.File, 0, len(pkg.Files))
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+
+		tp, _ := conf.Check(pkgName, fset, files, nil)
+		if tp == nil {
+			continue
+		}
+
+		obj, ok := tp.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			return nil, fmt.Errorf("type %s is not a struct", name)
+		}
+
+		m := &mirror{
+			qualifier:  func(p *types.Package) string { return p.Name() },
+			fieldTypes: map[string]string{},
+			cycles:     map[string]string{},
+			recursive:  map[string]bool{},
+			visiting:   map[*types.Named]bool{},
+		}
+
+		if named, ok := obj.Type().(*types.Named); ok {
+			m.visiting[named] = true
+		}
+
+		return &configStruct{
+			Name:       name,
+			Type:       m.structOf(st, ""),
+			FieldTypes: m.fieldTypes,
+			Cycles:     m.cycles,
+			Recursive:  m.recursive,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("type %s is not found in %s", name, dir)
+}
+
+// mirror builds the reflect types which mirror the static Go types.
+type mirror struct {
+	qualifier  types.Qualifier
+	fieldTypes map[string]string
+	cycles     map[string]string
+	recursive  map[string]bool
+	visiting   map[*types.Named]bool
+}
+
+// structOf returns a struct type with the exported fields and the tags of
+// the given struct. The path is the Go path of the struct, and it is used
+// for recording the Go types of the fields.
+func (m *mirror) structOf(st *types.Struct, path string) reflect.Type {
+	fields := make([]reflect.StructField, 0, st.NumFields())
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+
+		// Skip unexported fields
+		if !f.Exported() {
+			continue
+		}
+
+		fieldPath := f.Name()
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		m.fieldTypes[fieldPath] = types.TypeString(f.Type(), m.qualifier)
+		fields = append(fields, reflect.StructField{
+			Name: f.Name(),
+			Type: m.typeOf(f.Type(), fieldPath),
+			Tag:  reflect.StructTag(st.Tag(i)),
+		})
+	}
+
+	return reflect.StructOf(fields)
+}
+
+// typeOf returns the reflect type which mirrors the given Go type. The types
+// which cannot be mirrored, e.g. channels, are mirrored as interface{}, which
+// is an unsupported type for eco as well. The pointers to the recursive struct
+// types are mirrored as cycleValue pointers. The slices of them, which eco binds
+// only if their variables are set, cannot be mirrored with reflect.StructOf, so
+// they are mirrored as maps, which read all the variables under their prefix,
+// e.g. APP_CHILDREN_0_NAME, without checking their values. The other
+// references to them are mirrored as empty structs.
+func (m *mirror) typeOf(t types.Type, path string) reflect.Type {
+	if named, ok := t.(*types.Named); ok {
+		switch types.TypeString(named, nil) {
+		case "time.Duration":
+			return durationType
+		case "time.Time":
+			return timeType
+		case "github.com/orkungursel/go-eco.Secret":
+			return secretType
+		}
+
+		if isTextType(named) {
+			return textType
+		}
+
+		if m.visiting[named] {
+			return emptyType
+		}
+
+		m.visiting[named] = true
+		defer delete(m.visiting, named)
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if rt, ok := basicTypes[u.Kind()]; ok {
+			return rt
+		}
+	case *types.Pointer:
+		if named, ok := u.Elem().(*types.Named); ok && m.visiting[named] {
+			m.cycles[path] = types.TypeString(named, m.qualifier)
+			return reflect.PtrTo(cycleType)
+		}
+		return reflect.PtrTo(m.typeOf(u.Elem(), path))
+	case *types.Slice:
+		if m.isVisiting(u.Elem()) {
+			m.recursive[path] = true
+			return prefixType
+		}
+		return reflect.SliceOf(m.typeOf(u.Elem(), path+indexPlaceholder))
+	case *types.Map:
+		return reflect.MapOf(m.typeOf(u.Key(), path), m.typeOf(u.Elem(), path))
+	case *types.Struct:
+		return m.structOf(u, path)
+	}
+
+	return anyType
+}
+
+// isVisiting reports whether the given type, or the type which it points to,
+// is a named struct type which is being mirrored, e.g. Node in []*Node.
+func (m *mirror) isVisiting(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+	if !ok || !m.visiting[named] {
+		return false
+	}
+
+	_, ok = named.Underlying().(*types.Struct)
+	return ok
+}
+
+// isTextType reports whether the given type decodes itself,
+// either with encoding.TextUnmarshaler or with eco.Decoder.
+func isTextType(t *types.Named) bool {
+	ms := types.NewMethodSet(types.NewPointer(t))
+	return ms.Lookup(nil, "UnmarshalText") != nil || ms.Lookup(nil, "DecodeEnv") != nil
+}
@@ -0,0 +1,206 @@
+// Command eco inspects the config structs of a Go package statically and
+// prints the environment variables which would be read by eco, without
+// running the program.
+//
+// Usage:
+//
+//	eco vars -type Config [-prefix APP] [-separator _] [-format usage] [dir]
+//	eco check -type Config [-prefix APP] [-separator _] [-env-file .env] [dir]
+//
+// The dir is the directory of the Go package, which is the current
+// directory by default. The format is one of "usage", "dotenv" and
+// "markdown".
+//
+// The check command reports the missing required variables, the values
+// which cannot be converted to the types of their fields and, if a prefix
+// is given, the prefixed variables which are not read by any field. It
+// checks the given dotenv files, which can be repeated, or the environment
+// of the process if none is given, and exits with 1 if there are problems.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	eco "github.com/orkungursel/go-eco"
+)
+
+const usage = `Usage:
+
+	eco vars -type Config [-prefix APP] [-separator _] [-format usage] [dir]
+	eco check -type Config [-prefix APP] [-separator _] [-env-file .env] [dir]
+
+Commands:
+
+	vars	print the environment variables which are read by a config struct
+	check	check an environment against a config struct
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command with the given arguments,
+// and returns the exit code of the process.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "vars":
+		return runVars(args[1:], stdout, stderr)
+	case "check":
+		return runCheck(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "eco: unknown command %q\n\n%s", args[0], usage)
+	return 2
+}
+
+// instance is the part of the eco instance which is used by the commands.
+type instance interface {
+	Describe(v interface{}) (eco.Variables, error)
+	Unmarshal(v interface{}) error
+}
+
+// structFlags are the flags for selecting a config struct
+// and the naming rules of its environment variables.
+type structFlags struct {
+	typeName  string
+	prefix    string
+	separator string
+}
+
+// register registers the flags to the given flag set.
+func (f *structFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.typeName, "type", "", "name of the config struct `type` (required)")
+	fs.StringVar(&f.prefix, "prefix", "", "`prefix` of the environment variable names")
+	fs.StringVar(&f.separator, "separator", "_", "`separator` of the environment variable name parts")
+}
+
+// load loads the config struct from the package in the given directory,
+// and returns it with an eco instance which uses the naming rules and
+// reads the given sources, or the environment of the process if none.
+// The instance is in strict mode, so that the unknown prefixed variables
+// are reported.
+func (f *structFlags) load(dir string, sources ...eco.Source) (*configStruct, instance, error) {
+	if f.typeName == "" {
+		return nil, nil, fmt.Errorf("-type is required")
+	}
+
+	cs, err := loadStruct(dir, f.typeName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e := eco.New().
+		SetPrefix(f.prefix).
+		SetEnvNameSeparator(f.separator).
+		SetStrict(true).
+		SetSources(sources...)
+
+	return cs, e, nil
+}
+
+// runVars runs the "vars" command.
+func runVars(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("vars", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var sf structFlags
+	sf.register(fs)
+	format := fs.String("format", "usage", "output `format`: usage, dotenv or markdown")
+
+	dir, err := parseArgs(fs, args)
+	if err != nil {
+		return 2
+	}
+
+	cs, e, err := sf.load(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "eco: %v\n", err)
+		return 1
+	}
+
+	vars, err := describe(cs, e)
+	if err != nil {
+		fmt.Fprintf(stderr, "eco: %v\n", err)
+		return 1
+	}
+
+	switch *format {
+	case "usage":
+		fmt.Fprint(stdout, vars.Usage())
+	case "dotenv":
+		fmt.Fprint(stdout, vars.DotenvExample())
+	case "markdown":
+		fmt.Fprint(stdout, vars.Markdown())
+	default:
+		fmt.Fprintf(stderr, "eco: unknown format %q\n", *format)
+		return 2
+	}
+
+	return 0
+}
+
+// describe returns the environment variables of the given config struct,
+// with the Go types and the field paths of the static struct.
+func describe(cs *configStruct, e instance) (eco.Variables, error) {
+	vars, err := e.Describe(cs.New())
+	if err != nil {
+		return nil, err
+	}
+
+	described := vars[:0]
+	for _, v := range vars {
+		// skip the slices of the recursive types like Describe
+		if cs.Recursive[v.Field] {
+			continue
+		}
+
+		if t, ok := cs.FieldTypes[v.Field]; ok {
+			v.Type = t
+		}
+		v.Field = cs.FieldPath(v.Field)
+		described = append(described, v)
+	}
+
+	return described, nil
+}
+
+// parseArgs parses the flags, which may be given before or after the
+// directory argument, and returns the directory, "." by default.
+func parseArgs(fs *flag.FlagSet, args []string) (string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return "", err
+		}
+
+		if fs.NArg() == 0 {
+			break
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	switch len(positional) {
+	case 0:
+		return ".", nil
+	case 1:
+		return positional[0], nil
+	}
+
+	err := fmt.Errorf("too many arguments: %v", positional)
+	fmt.Fprintln(fs.Output(), err)
+	fs.Usage()
+	return "", err
+}
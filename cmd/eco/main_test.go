@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun_Vars(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     string
+		wantErr  string
+		wantCode int
+	}{
+		{
+			name: "should print the variables",
+			args: []string{"vars", "-type", "Config", "-prefix", "APP", "./testdata/config"},
+			want: `Environment variables:
+  APP_HOST                string             Host of the server (required)
+  APP_PORT                int                (default "8080")
+  APP_DEBUG               bool
+  APP_TIMEOUT             time.Duration      (default "5s")
+  APP_LEVEL               config.Level       (default "info")
+  APP_TAGS                []string
+  APP_LABELS              map[string]string
+  APP_NAME                string
+  APP_DB_URL              string             (required)
+  APP_UPSTREAMS_{N}_HOST  string
+  APP_UPSTREAMS_{N}_PORT  int
+  APP_TREE_NAME           string
+  APP_EVENTS              chan string
+`,
+		},
+		{
+			name: "should skip the slices of recursive types",
+			args: []string{"vars", "-type", "Node", "-prefix", "APP", "./testdata/tree"},
+			want: "Environment variables:\n  APP_NAME  string\n",
+		},
+		{
+			name: "should accept the flags after the directory",
+			args: []string{"vars", "./testdata/config", "-type", "Config", "-separator", "__", "-format", "dotenv"},
+			want: "# Host of the server\n# type: string, required\nHOST=\n",
+		},
+		{
+			name: "should redact the secret values",
+			args: []string{"vars", "-type", "Config", "./testdata/secret"},
+			want: "Environment variables:\n" +
+				"  PASSWORD  eco.Secret  (default \"[REDACTED]\")\n" +
+				"  PIN       int\n",
+		},
+		{
+			name:     "should fail if the type is not given",
+			args:     []string{"vars", "./testdata/config"},
+			wantErr:  "-type is required",
+			wantCode: 1,
+		},
+		{
+			name:     "should fail if the type is not found",
+			args:     []string{"vars", "-type", "Missing", "./testdata/config"},
+			wantErr:  "type Missing is not found",
+			wantCode: 1,
+		},
+		{
+			name:     "should fail if the type is not a struct",
+			args:     []string{"vars", "-type", "NotStruct", "./testdata/config"},
+			wantErr:  "type NotStruct is not a struct",
+			wantCode: 1,
+		},
+		{
+			name:     "should fail if the format is unknown",
+			args:     []string{"vars", "-type", "Config", "-format", "xml", "./testdata/config"},
+			wantErr:  `unknown format "xml"`,
+			wantCode: 2,
+		},
+		{
+			name:     "should fail if the command is unknown",
+			args:     []string{"foo"},
+			wantErr:  `unknown command "foo"`,
+			wantCode: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Fatalf("run() = %d, want %d, stderr: %s", code, tt.wantCode, stderr.String())
+			}
+
+			if !strings.Contains(stderr.String(), tt.wantErr) {
+				t.Errorf("run() stderr = %q, want %q", stderr.String(), tt.wantErr)
+			}
+
+			if tt.want != "" && !strings.HasPrefix(stdout.String(), tt.want) {
+				t.Errorf("run() stdout = %q, want %q", stdout.String(), tt.want)
+			}
+		})
+	}
+}
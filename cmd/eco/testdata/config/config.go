@@ -0,0 +1,43 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+type Level string
+
+func (l *Level) UnmarshalText(text []byte) error {
+	*l = Level(strings.ToLower(string(text)))
+	return nil
+}
+
+type Node struct {
+	Name string
+	Next *Node
+}
+
+type Config struct {
+	Host    string `env:"HOST,required" desc:"Host of the server"`
+	Port    int    `default:"8080"`
+	Debug   bool
+	Timeout time.Duration `default:"5s"`
+	Level   Level         `default:"info"`
+	Tags    []string
+	Labels  map[string]string
+	Skipped struct {
+		Name string
+	} `env:"-"`
+	DB *struct {
+		URL string `required:"true"`
+	}
+	Upstreams []struct {
+		Host string
+		Port int
+	}
+	Tree   Node
+	Events chan string
+	secret string
+}
+
+type NotStruct int
@@ -0,0 +1,8 @@
+package secret
+
+import eco "github.com/orkungursel/go-eco"
+
+type Config struct {
+	Password eco.Secret `default:"changeme"`
+	Pin      int        `secret:"true"`
+}
@@ -0,0 +1,7 @@
+package tree
+
+type Node struct {
+	Name     string
+	Children []Node
+	Links    []*Node
+}
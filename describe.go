@@ -0,0 +1,249 @@
+package eco
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// indexPlaceholder is the name part which stands for the indices
+// of the slices of structs, e.g. UPSTREAMS_{N}_HOST.
+const indexPlaceholder = "{N}"
+
+// Variable describes an environment variable which is read by Unmarshal.
+type Variable struct {
+	// Name is the name of the environment variable.
+	Name string
+	// Field is the Go path of the field, e.g. "Config.DB.Host".
+	Field string
+	// Type is the Go type of the field, e.g. "time.Duration".
+	Type string
+	// Default is the value of the "default" tag.
+	Default string
+	// Required reports whether the field is required.
+	Required bool
+	// Description is the value of the "desc" tag.
+	Description string
+	// Example is the value of the "example" tag.
+	Example string
+	// Secret reports whether the field is a secret field. The default
+	// and the example values of the secret fields are redacted.
+	Secret bool
+}
+
+// Variables is a list of the environment variables.
+type Variables []Variable
+
+// Describe takes a struct, or a pointer to a struct, and returns the
+// environment variables which would be read by Unmarshal, in the order of
+// the fields. The indices of the slices of structs are shown as "{N}".
+func (e *eco) Describe(v interface{}) (Variables, error) {
+	if v == nil {
+		return nil, ErrRequiresStruct
+	}
+
+	t := derefType(reflect.TypeOf(v))
+	if t.Kind() != reflect.Struct {
+		return nil, ErrRequiresStruct
+	}
+
+	var p []string
+	if prefix := e.getPrefix(); prefix != "" {
+		p = append(p, prefix)
+	}
+
+	var vars Variables
+	e.describeStructValues(t, &vars, nil, t.Name(), p...)
+
+	return vars, nil
+}
+
+// describeStructValues appends the environment variables
+// of the fields of the given struct type to the given list.
+// The parents are the struct types which are being described,
+// and the recursive fields of them are not described again.
+func (e *eco) describeStructValues(t reflect.Type, vars *Variables, parents []reflect.Type, fieldPath string, envNameParts ...string) {
+	parents = append(parents[:len(parents):len(parents)], t)
+
+	for i := 0; i < t.NumField(); i++ {
+		typeField := t.Field(i)
+
+		// Skip unexported fields
+		if !typeField.IsExported() {
+			continue
+		}
+
+		tags := typeField.Tag
+		path := joinFieldPath(fieldPath, typeField.Name)
+		p, envKey, envTagOpts := e.getFieldEnvName(typeField, envNameParts)
+
+		// if field is a slice of structs, describe its indexed variables
+		if e.isStructSlice(typeField.Type) {
+			if et := derefType(typeField.Type.Elem()); !isParentType(parents, et) {
+				ep := append(append([]string{}, p...), indexPlaceholder)
+				e.describeStructValues(et, vars, parents, path+"["+indexPlaceholder+"]", ep...)
+			}
+			continue
+		}
+
+		// if field is a struct, describe its fields
+		if e.isNestedStruct(typeField.Type) {
+			if ft := derefType(typeField.Type); !isParentType(parents, ft) {
+				e.describeStructValues(ft, vars, parents, path, p...)
+			}
+			continue
+		}
+
+		v := Variable{
+			Name:        envKey,
+			Field:       path,
+			Type:        typeField.Type.String(),
+			Default:     tags.Get(e.tagNameDefault),
+			Required:    e.isRequired(tags, envTagOpts),
+			Description: tags.Get(e.tagNameDescription),
+			Example:     tags.Get(e.tagNameExample),
+			Secret:      e.isSecret(typeField),
+		}
+
+		if v.Secret {
+			v.Default = redact(v.Default)
+			v.Example = redact(v.Example)
+		}
+
+		*vars = append(*vars, v)
+	}
+}
+
+// isParentType reports whether the given type is one of the given parents.
+func isParentType(parents []reflect.Type, t reflect.Type) bool {
+	for _, pt := range parents {
+		if pt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DotenvExample renders the variables as a ".env.example" file, where each
+// variable has its example or default value and is preceded by comments
+// with its description, type, default value and whether it is required
+// or secret.
+func (vars Variables) DotenvExample() string {
+	var b strings.Builder
+	for i, v := range vars {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		if v.Description != "" {
+			fmt.Fprintf(&b, "# %s\n", strings.ReplaceAll(v.Description, "\n", "\n# "))
+		}
+
+		details := []string{"type: " + v.Type}
+		if v.Required {
+			details = append(details, "required")
+		}
+		if v.Secret {
+			details = append(details, "secret")
+		}
+		if v.Default != "" {
+			details = append(details, "default: "+v.Default)
+		}
+		fmt.Fprintf(&b, "# %s\n", strings.Join(details, ", "))
+
+		// the values of the secret fields are left empty
+		val := v.Example
+		if val == "" {
+			val = v.Default
+		}
+		if v.Secret {
+			val = ""
+		}
+		fmt.Fprintf(&b, "%s=%s\n", v.Name, quoteDotenvValue(val))
+	}
+
+	return b.String()
+}
+
+// Markdown renders the variables as a Markdown table.
+func (vars Variables) Markdown() string {
+	var b strings.Builder
+	b.WriteString("| Name | Type | Default | Required | Description |\n")
+	b.WriteString("|------|------|---------|----------|-------------|\n")
+
+	for _, v := range vars {
+		required := "no"
+		if v.Required {
+			required = "yes"
+		}
+
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			markdownCode(v.Name),
+			markdownCode(v.Type),
+			markdownCode(v.Default),
+			required,
+			markdownEscape(v.Description),
+		)
+	}
+
+	return b.String()
+}
+
+// Usage renders the variables as a plain text usage output,
+// similar to the output of the flag package.
+func (vars Variables) Usage() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Environment variables:")
+	for _, v := range vars {
+		desc := v.Description
+		if v.Required {
+			desc = strings.TrimSpace(desc + " (required)")
+		}
+		if v.Default != "" {
+			desc = strings.TrimSpace(fmt.Sprintf("%s (default %q)", desc, v.Default))
+		}
+
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", v.Name, v.Type, desc)
+	}
+
+	_ = w.Flush()
+
+	// the empty descriptions leave the padding of the type column behind
+	lines := strings.SplitAfter(buf.String(), "\n")
+	for i, line := range lines {
+		if strings.HasSuffix(line, " \n") {
+			lines[i] = strings.TrimRight(line, " \n") + "\n"
+		}
+	}
+
+	return strings.Join(lines, "")
+}
+
+// quoteDotenvValue quotes the given value for a dotenv file if it contains
+// the characters which would be interpreted by the dotenv parser.
+func quoteDotenvValue(val string) string {
+	if !strings.ContainsAny(val, " \t\n\"'#$\\") {
+		return val
+	}
+
+	return "'" + strings.ReplaceAll(val, "'", `'"'"'`) + "'"
+}
+
+// markdownCode returns the given value as inline code for a Markdown table.
+func markdownCode(val string) string {
+	if val == "" {
+		return ""
+	}
+
+	return "`" + markdownEscape(val) + "`"
+}
+
+// markdownEscape escapes the characters of the given value which would
+// break a Markdown table.
+func markdownEscape(val string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(val)
+}
@@ -0,0 +1,155 @@
+package eco
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type SampleDescribeStruct struct {
+	Host     string        `env:"HOST,required" desc:"Host of the server" example:"localhost"`
+	Port     int           `default:"8080" desc:"Port of the server"`
+	Timeout  time.Duration `default:"5s"`
+	Tags     []string
+	Password Secret `default:"changeme" example:"s3cr3t"`
+	Server   struct {
+		Name string
+	} `env:"-"`
+	DB *struct {
+		URL string `required:"true" desc:"Database URL" example:"postgres://user@db/app"`
+	}
+	Upstreams []struct {
+		Host string
+	}
+	unexported string
+}
+
+func TestEco_Describe(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		args    interface{}
+		want    Variables
+		wantErr error
+	}{
+		{
+			name:   "should describe all fields",
+			prefix: "APP",
+			args:   SampleDescribeStruct{},
+			want: Variables{
+				{Name: "APP_HOST", Field: "SampleDescribeStruct.Host", Type: "string", Required: true, Description: "Host of the server", Example: "localhost"},
+				{Name: "APP_PORT", Field: "SampleDescribeStruct.Port", Type: "int", Default: "8080", Description: "Port of the server"},
+				{Name: "APP_TIMEOUT", Field: "SampleDescribeStruct.Timeout", Type: "time.Duration", Default: "5s"},
+				{Name: "APP_TAGS", Field: "SampleDescribeStruct.Tags", Type: "[]string"},
+				{Name: "APP_PASSWORD", Field: "SampleDescribeStruct.Password", Type: "eco.Secret", Default: "[REDACTED]", Example: "[REDACTED]", Secret: true},
+				{Name: "APP_NAME", Field: "SampleDescribeStruct.Server.Name", Type: "string"},
+				{Name: "APP_DB_URL", Field: "SampleDescribeStruct.DB.URL", Type: "string", Required: true, Description: "Database URL", Example: "postgres://user@db/app"},
+				{Name: "APP_UPSTREAMS_{N}_HOST", Field: "SampleDescribeStruct.Upstreams[{N}].Host", Type: "string"},
+			},
+		},
+		{
+			name: "should describe a nil pointer to a struct",
+			args: (*struct {
+				Name string
+			})(nil),
+			want: Variables{
+				{Name: "NAME", Field: "Name", Type: "string"},
+			},
+		},
+		{
+			name:    "should return error if it is not a struct",
+			args:    "string",
+			wantErr: ErrRequiresStruct,
+		},
+		{
+			name:    "should return error if it is nil",
+			args:    nil,
+			wantErr: ErrRequiresStruct,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().SetPrefix(tt.prefix).Describe(tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Describe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Describe() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariables_DotenvExample(t *testing.T) {
+	vars := Variables{
+		{Name: "HOST", Type: "string", Required: true, Description: "Host of the server", Example: "localhost"},
+		{Name: "PORT", Type: "int", Default: "8080"},
+		{Name: "GREETING", Type: "string", Example: "hello world"},
+		{Name: "PASSWORD", Type: "eco.Secret", Default: "[REDACTED]", Secret: true},
+	}
+
+	want := `# Host of the server
+# type: string, required
+HOST=localhost
+
+# type: int, default: 8080
+PORT=8080
+
+# type: string
+GREETING='hello world'
+
+# type: eco.Secret, secret, default: [REDACTED]
+PASSWORD=
+`
+
+	if got := vars.DotenvExample(); got != want {
+		t.Errorf("DotenvExample() = %q, want %q", got, want)
+	}
+
+	// the rendered file must be readable by the dotenv parser
+	got, err := parseDotenv(".env.example", vars.DotenvExample(), nil)
+	if err != nil {
+		t.Fatalf("parseDotenv() error = %v", err)
+	}
+
+	wantVars := map[string]string{"HOST": "localhost", "PORT": "8080", "GREETING": "hello world", "PASSWORD": ""}
+	if !reflect.DeepEqual(got, wantVars) {
+		t.Errorf("parseDotenv() = %v, want %v", got, wantVars)
+	}
+}
+
+func TestVariables_Markdown(t *testing.T) {
+	vars := Variables{
+		{Name: "HOST", Type: "string", Required: true, Description: "Host of the server"},
+		{Name: "LEVEL", Type: "string", Default: "info", Description: "debug|info"},
+	}
+
+	want := "| Name | Type | Default | Required | Description |\n" +
+		"|------|------|---------|----------|-------------|\n" +
+		"| `HOST` | `string` |  | yes | Host of the server |\n" +
+		"| `LEVEL` | `string` | `info` | no | debug\\|info |\n"
+
+	if got := vars.Markdown(); got != want {
+		t.Errorf("Markdown() = %q, want %q", got, want)
+	}
+}
+
+func TestVariables_Usage(t *testing.T) {
+	vars := Variables{
+		{Name: "HOST", Type: "string", Required: true, Description: "Host of the server"},
+		{Name: "TIMEOUT", Type: "time.Duration", Default: "5s"},
+		{Name: "DEBUG", Type: "bool"},
+	}
+
+	want := "Environment variables:\n" +
+		"  HOST     string         Host of the server (required)\n" +
+		"  TIMEOUT  time.Duration  (default \"5s\")\n" +
+		"  DEBUG    bool\n"
+
+	if got := vars.Usage(); got != want {
+		t.Errorf("Usage() = %q, want %q", got, want)
+	}
+}
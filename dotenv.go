@@ -0,0 +1,391 @@
+package eco
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultDotenvPath is the file which is read when no path is given.
+const defaultDotenvPath = ".env"
+
+// DotenvError describes a syntax error in a dotenv file.
+type DotenvError struct {
+	// File is the name of the dotenv file.
+	File string
+	// Line is the line number, starting from 1, where the error occurred.
+	Line int
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *DotenvError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DotenvError) Unwrap() error {
+	return e.Err
+}
+
+// ReadDotenv reads the given dotenv files and returns their variables.
+// If a variable is defined in more than one file, the value of the first
+// file is used. If no path is given, ".env" is read.
+func ReadDotenv(paths ...string) (map[string]string, error) {
+	if len(paths) == 0 {
+		paths = []string{defaultDotenvPath}
+	}
+
+	vars := map[string]string{}
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		fileVars, err := parseDotenv(path, string(b), func(name string) (string, bool) {
+			if v, ok := vars[name]; ok {
+				return v, true
+			}
+			return os.LookupEnv(name)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range fileVars {
+			if _, ok := vars[k]; !ok {
+				vars[k] = v
+			}
+		}
+	}
+
+	return vars, nil
+}
+
+// LoadDotenv reads the given dotenv files and sets their variables into
+// the environment of the process. Variables which are already set in the
+// environment are not overridden. If no path is given, ".env" is read.
+func LoadDotenv(paths ...string) error {
+	vars, err := ReadDotenv(paths...)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range vars {
+		if _, ok := os.LookupEnv(k); ok {
+			continue
+		}
+
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DotenvValueGetter reads the given dotenv files and returns a function for
+// getting the environment variable values, which can be used with
+// SetValueGetter. Variables which are set in the environment of the process
+// take precedence over the ones in the files.
+func DotenvValueGetter(paths ...string) (envValueGetterFunc, error) {
+	vars, err := ReadDotenv(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(key string) string {
+		if v, ok := os.LookupEnv(key); ok {
+			return v
+		}
+		return vars[key]
+	}, nil
+}
+
+// dotenvParser parses the contents of a single dotenv file.
+type dotenvParser struct {
+	name   string
+	src    string
+	pos    int
+	line   int
+	vars   map[string]string
+	lookup func(name string) (string, bool)
+}
+
+// parseDotenv parses the given dotenv file contents. The variable references,
+// e.g. ${HOME}, are resolved from the variables which are defined before them
+// in the same file, and then with the given lookup function.
+func parseDotenv(name, src string, lookup func(name string) (string, bool)) (map[string]string, error) {
+	p := &dotenvParser{
+		name:   name,
+		src:    strings.ReplaceAll(src, "\r\n", "\n"),
+		line:   1,
+		vars:   map[string]string{},
+		lookup: lookup,
+	}
+
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+
+	return p.vars, nil
+}
+
+// parse parses all the lines of the file.
+func (p *dotenvParser) parse() error {
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+
+		switch p.peek() {
+		case '\n':
+			p.next()
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+
+		if err := p.parseVariable(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseVariable parses a single variable definition, e.g. `export FOO="bar"`.
+func (p *dotenvParser) parseVariable() error {
+	if rest := p.src[p.pos:]; strings.HasPrefix(rest, "export ") || strings.HasPrefix(rest, "export\t") {
+		p.pos += len("export")
+		p.skipBlank()
+	}
+
+	start := p.pos
+	for !p.eof() && isDotenvNameChar(p.peek()) {
+		p.next()
+	}
+
+	key := p.src[start:p.pos]
+	if key == "" || !isDotenvNameStart(key[0]) {
+		return p.errorf("invalid variable name")
+	}
+
+	p.skipBlank()
+	if p.eof() || p.peek() != '=' {
+		return p.errorf("missing '=' after %s", key)
+	}
+	p.next()
+
+	valuePos := p.pos
+	p.skipBlank()
+
+	var val string
+	var err error
+
+	switch {
+	case p.eof():
+	case p.peek() == '#' && p.pos > valuePos:
+		// an inline comment without a value, e.g. `FOO= # comment`
+		p.skipLine()
+	case p.peek() == '\'':
+		val, err = p.parseQuoted('\'')
+	case p.peek() == '"':
+		val, err = p.parseQuoted('"')
+	default:
+		val, err = p.parseUnquoted()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	p.vars[key] = val
+	return nil
+}
+
+// parseQuoted parses a single or double quoted value, which may span
+// multiple lines. Escape sequences and variable references are only
+// processed in double quoted values.
+func (p *dotenvParser) parseQuoted(quote byte) (string, error) {
+	line := p.line
+	p.next()
+
+	start := p.pos
+	for {
+		if p.eof() {
+			p.line = line
+			return "", p.errorf("unterminated quoted value")
+		}
+
+		c := p.next()
+		if c == '\\' && quote == '"' && !p.eof() {
+			p.next()
+			continue
+		}
+
+		if c == quote {
+			break
+		}
+	}
+
+	raw := p.src[start : p.pos-1]
+
+	// only a comment may follow the closing quote
+	p.skipBlank()
+	if !p.eof() && p.peek() != '\n' && p.peek() != '#' {
+		return "", p.errorf("unexpected character %q after quoted value", p.peek())
+	}
+	p.skipLine()
+
+	if quote == '\'' {
+		return raw, nil
+	}
+
+	return p.expand(raw, true, line)
+}
+
+// parseUnquoted parses an unquoted value until the end of the line
+// or an inline comment, which starts with a whitespace and "#".
+func (p *dotenvParser) parseUnquoted() (string, error) {
+	line := p.line
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		if c := p.next(); (c == ' ' || c == '\t') && !p.eof() && p.peek() == '#' {
+			break
+		}
+	}
+
+	raw := strings.TrimSpace(p.src[start:p.pos])
+	p.skipLine()
+
+	return p.expand(raw, false, line)
+}
+
+// expand resolves the escape sequences, e.g. \n, if enabled, and then the
+// variable references, e.g. ${FOO}, $FOO or ${FOO:-default}, with the same
+// semantics as the expansion of the field values. An escaped "\$" is kept
+// as a literal "$".
+func (p *dotenvParser) expand(s string, escapes bool, line int) (string, error) {
+	if escapes {
+		s = unescapeDotenv(s)
+	}
+
+	v, err := expandVars(s, p.resolve)
+	if err != nil {
+		return "", &DotenvError{File: p.name, Line: line, Err: err}
+	}
+
+	return v, nil
+}
+
+// unescapeDotenv resolves the escape sequences of a double quoted value.
+// The escaped "$" is returned as "$$", which is a literal "$" for expandVars.
+func unescapeDotenv(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '$':
+			b.WriteString("$$")
+		case '\\', '"':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+// resolve returns the value of the referenced variable, and reports
+// whether it is defined.
+func (p *dotenvParser) resolve(name string) (string, bool, error) {
+	if v, ok := p.vars[name]; ok {
+		return v, true, nil
+	}
+
+	if p.lookup != nil {
+		if v, ok := p.lookup(name); ok {
+			return v, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// eof reports whether the end of the file is reached.
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+// peek returns the current character.
+func (p *dotenvParser) peek() byte {
+	return p.src[p.pos]
+}
+
+// next returns the current character and advances to the next one.
+func (p *dotenvParser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipBlank skips the spaces and tabs.
+func (p *dotenvParser) skipBlank() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.next()
+	}
+}
+
+// skipLine skips the rest of the current line, including the line break.
+func (p *dotenvParser) skipLine() {
+	for !p.eof() {
+		if p.next() == '\n' {
+			return
+		}
+	}
+}
+
+// errorf returns a DotenvError for the current line.
+func (p *dotenvParser) errorf(format string, args ...interface{}) error {
+	return &DotenvError{
+		File: p.name,
+		Line: p.line,
+		Err:  fmt.Errorf(format, args...),
+	}
+}
+
+// isDotenvNameStart reports whether the given character
+// can be the first character of a variable name.
+func isDotenvNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isDotenvNameChar reports whether the given character
+// can be used in a variable name.
+func isDotenvNameChar(c byte) bool {
+	return isDotenvNameStart(c) || (c >= '0' && c <= '9') || c == '.' || c == '-'
+}
@@ -0,0 +1,278 @@
+package eco
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_parseDotenv(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		lookup   map[string]string
+		want     map[string]string
+		wantErr  bool
+		wantLine int
+	}{
+		{
+			name: "should parse unquoted values",
+			src:  "FOO=bar\nBAZ = qux \n\n# comment\nEMPTY=\n",
+			want: map[string]string{
+				"FOO":   "bar",
+				"BAZ":   "qux",
+				"EMPTY": "",
+			},
+		},
+		{
+			name: "should parse export prefix",
+			src:  "export FOO=bar\nexport\tBAZ=qux\nexport=value",
+			want: map[string]string{
+				"FOO":    "bar",
+				"BAZ":    "qux",
+				"export": "value",
+			},
+		},
+		{
+			name: "should strip inline comments",
+			src:  "FOO=bar # comment\nBAZ=qux#notcomment\nEMPTY= # comment\nQUOTED=\"a # b\" # comment",
+			want: map[string]string{
+				"FOO":    "bar",
+				"BAZ":    "qux#notcomment",
+				"EMPTY":  "",
+				"QUOTED": "a # b",
+			},
+		},
+		{
+			name: "should keep single quoted values literal",
+			src:  `FOO='bar\n ${BAZ} "qux"'`,
+			lookup: map[string]string{
+				"BAZ": "baz",
+			},
+			want: map[string]string{
+				"FOO": `bar\n ${BAZ} "qux"`,
+			},
+		},
+		{
+			name: "should process escapes in double quoted values",
+			src:  `FOO="a\nb\tc \"d\" \\ \$HOME \x"`,
+			want: map[string]string{
+				"FOO": "a\nb\tc \"d\" \\ $HOME \\x",
+			},
+		},
+		{
+			name: "should parse multiline values",
+			src:  "FOO=\"line 1\nline 2\"\nBAR='line 3\nline 4'\nBAZ=baz",
+			want: map[string]string{
+				"FOO": "line 1\nline 2",
+				"BAR": "line 3\nline 4",
+				"BAZ": "baz",
+			},
+		},
+		{
+			name: "should expand variable references",
+			src:  "HOST=localhost\nURL=http://${HOST}:$PORT/path\nQUOTED=\"${HOST}/${MISSING}\"",
+			lookup: map[string]string{
+				"PORT": "8080",
+				"HOST": "not used",
+			},
+			want: map[string]string{
+				"HOST":   "localhost",
+				"URL":    "http://localhost:8080/path",
+				"QUOTED": "localhost/",
+			},
+		},
+		{
+			name: "should expand the fallback references",
+			src:  "A=${X:-def}\nB=\"${X:-${A}}/\\${A}\"\nC=$$A\nD=${A:?a is required}",
+			want: map[string]string{
+				"A": "def",
+				"B": "def/${A}",
+				"C": "$A",
+				"D": "def",
+			},
+		},
+		{
+			name: "should handle windows line endings",
+			src:  "FOO=bar\r\nBAZ=\"a\r\nb\"\r\n",
+			want: map[string]string{
+				"FOO": "bar",
+				"BAZ": "a\nb",
+			},
+		},
+		{
+			name:     "should error on missing separator",
+			src:      "FOO=bar\n\nBAZ qux",
+			wantErr:  true,
+			wantLine: 3,
+		},
+		{
+			name:     "should error on invalid name",
+			src:      "FOO=bar\n1FOO=bar",
+			wantErr:  true,
+			wantLine: 2,
+		},
+		{
+			name:     "should error on unterminated quote",
+			src:      "FOO=bar\nBAZ=\"qux\n\n",
+			wantErr:  true,
+			wantLine: 2,
+		},
+		{
+			name:     "should error on characters after quote",
+			src:      "FOO='bar' baz",
+			wantErr:  true,
+			wantLine: 1,
+		},
+		{
+			name:     "should error on unterminated reference",
+			src:      "FOO=bar\nBAZ=${FOO",
+			wantErr:  true,
+			wantLine: 2,
+		},
+		{
+			name:     "should error on unsupported reference",
+			src:      "FOO=bar\nBAZ=${FOO:+x}",
+			wantErr:  true,
+			wantLine: 2,
+		},
+		{
+			name:     "should error on required reference",
+			src:      "FOO=bar\n\nBAZ=\"${MISSING:?missing is required}\"",
+			wantErr:  true,
+			wantLine: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDotenv("test.env", tt.src, func(name string) (string, bool) {
+				v, ok := tt.lookup[name]
+				return v, ok
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDotenv() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				var derr *DotenvError
+				if !errors.As(err, &derr) || derr.File != "test.env" || derr.Line != tt.wantLine {
+					t.Errorf("parseDotenv() error = %v, want line %d", err, tt.wantLine)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDotenv() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadDotenv(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, ".env.local")
+	base := filepath.Join(dir, ".env")
+
+	writeFile(t, local, "FOO=local\nURL=${BASE}/local")
+	writeFile(t, base, "FOO=base\nBAR=base\nBASE=http://base")
+
+	t.Run("should give precedence to the first file", func(t *testing.T) {
+		got, err := ReadDotenv(base, local)
+		if err != nil {
+			t.Fatalf("ReadDotenv() error = %v", err)
+		}
+
+		want := map[string]string{
+			"FOO":  "base",
+			"BAR":  "base",
+			"BASE": "http://base",
+			"URL":  "http://base/local",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadDotenv() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("should error when file does not exist", func(t *testing.T) {
+		if _, err := ReadDotenv(filepath.Join(dir, "missing.env")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("ReadDotenv() error = %v, want %v", err, os.ErrNotExist)
+		}
+	})
+
+	t.Run("should read .env by default", func(t *testing.T) {
+		wd, _ := os.Getwd()
+		if err := os.Chdir(dir); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = os.Chdir(wd) }()
+
+		got, err := ReadDotenv()
+		if err != nil {
+			t.Fatalf("ReadDotenv() error = %v", err)
+		}
+		if got["FOO"] != "base" {
+			t.Errorf("ReadDotenv() = %v, want FOO=base", got)
+		}
+	})
+}
+
+func TestLoadDotenv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	writeFile(t, path, "ECO_DOTENV_FOO=file\nECO_DOTENV_BAR=file")
+
+	t.Setenv("ECO_DOTENV_FOO", "env")
+	t.Setenv("ECO_DOTENV_BAR", "")
+	if err := os.Unsetenv("ECO_DOTENV_BAR"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadDotenv(path); err != nil {
+		t.Fatalf("LoadDotenv() error = %v", err)
+	}
+
+	if got := os.Getenv("ECO_DOTENV_FOO"); got != "env" {
+		t.Errorf("ECO_DOTENV_FOO = %v, want env", got)
+	}
+	if got := os.Getenv("ECO_DOTENV_BAR"); got != "file" {
+		t.Errorf("ECO_DOTENV_BAR = %v, want file", got)
+	}
+}
+
+func TestDotenvValueGetter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	writeFile(t, path, "FOO=file\nSUB_BAR=file")
+
+	t.Setenv("FOO", "env")
+
+	getter, err := DotenvValueGetter(path)
+	if err != nil {
+		t.Fatalf("DotenvValueGetter() error = %v", err)
+	}
+
+	type Struct struct {
+		Foo string
+		Sub struct {
+			Bar string
+		}
+	}
+
+	got := &Struct{}
+	if err := New().SetValueGetter(getter).Unmarshal(got); err != nil {
+		t.Fatalf("Eco.Unmarshal() error = %v", err)
+	}
+
+	if got.Foo != "env" || got.Sub.Bar != "file" {
+		t.Errorf("Eco.Unmarshal() = %+v, want Foo=env and Sub.Bar=file", got)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,23 +1,54 @@
 package eco
 
 import (
-	"errors"
+	"encoding"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type eco struct {
 	sliceSeparator        string
+	mapPairSeparator      string
+	mapKeyValueSeparator  string
 	envNameSeparator      string
 	envNamePrefix         string
 	envNamePrefixAutoTrim bool
 	envNameTransformer    envNameTransformerFunc
-	envValueGetter        envValueGetterFunc
+	sources               []Source
+	emptyMode             EmptyMode
+	strict                bool
+	expand                bool
+	lazyStructs           bool
+	fileIndirection       bool
+	fileSizeLimit         int64
+	converters            map[reflect.Type]converterFunc
 	tagNameEnv            string
 	tagNameDefault        string
+	tagNameRequired       string
+	tagNameLayout         string
+	tagNameFile           string
+	tagNameSeparator      string
+	tagNameKeyValueSep    string
+	tagNameDescription    string
+	tagNameExample        string
+	tagNameSecret         string
+	tagNameNotEmpty       string
+	tagNameLen            string
+	tagNameMin            string
+	tagNameMax            string
+	tagNameOneOf          string
+	tagNamePattern        string
+	tagNameRequiredIf     string
+	tagNameRequiredUnless string
+	tagNameExcludes       string
+	tagNameOneOfGroup     string
+	tagNameExpand         string
 	tagSkipIdentifier     string
 }
 
@@ -25,12 +56,36 @@ type eco struct {
 func New() *eco {
 	return &eco{
 		sliceSeparator:        ",",
+		mapPairSeparator:      ",",
+		mapKeyValueSeparator:  ":",
 		envNameSeparator:      "_",
 		envNamePrefixAutoTrim: true,
 		envNameTransformer:    defaultEnvNameTransformerFunc,
-		envValueGetter:        os.Getenv,
+		sources:               []Source{EnvSource()},
+		emptyMode:             EmptyAsUnset,
+		fileSizeLimit:         defaultFileSizeLimit,
+		converters:            map[reflect.Type]converterFunc{},
 		tagNameEnv:            "env",
 		tagNameDefault:        "default",
+		tagNameRequired:       "required",
+		tagNameLayout:         "layout",
+		tagNameFile:           "file",
+		tagNameSeparator:      "sep",
+		tagNameKeyValueSep:    "kvsep",
+		tagNameDescription:    "desc",
+		tagNameExample:        "example",
+		tagNameSecret:         "secret",
+		tagNameNotEmpty:       "notempty",
+		tagNameLen:            "len",
+		tagNameMin:            "min",
+		tagNameMax:            "max",
+		tagNameOneOf:          "oneof",
+		tagNamePattern:        "pattern",
+		tagNameRequiredIf:     "requiredIf",
+		tagNameRequiredUnless: "requiredUnless",
+		tagNameExcludes:       "excludes",
+		tagNameOneOfGroup:     "oneOfGroup",
+		tagNameExpand:         "expand",
 		tagSkipIdentifier:     "-",
 	}
 }
@@ -68,6 +123,19 @@ func (e *eco) SetArraySeparator(arrSep string) *eco {
 	return e
 }
 
+// SetMapSeparators sets the separators for map values, e.g. "k1:v1,k2:v2".
+// Empty separators are ignored. Default is "," for the pairs and ":"
+// for the keys and values.
+func (e *eco) SetMapSeparators(pairSep, keyValueSep string) *eco {
+	if pairSep != "" {
+		e.mapPairSeparator = pairSep
+	}
+	if keyValueSep != "" {
+		e.mapKeyValueSeparator = keyValueSep
+	}
+	return e
+}
+
 // SetEnvNameTransformer sets the function for transforming the environment variable names.
 func (e *eco) SetEnvNameTransformer(transformerFunc envNameTransformerFunc) *eco {
 	e.envNameTransformer = transformerFunc
@@ -83,13 +151,157 @@ func (e *eco) SetEnvNameSeparator(envNameSeparator string) *eco {
 }
 
 // SetValueGetter sets the function for getting the environment variable values.
+// Since the function cannot tell whether a variable is set, empty values are
+// reported as unset. It replaces the sources which are set with SetSources.
 func (e *eco) SetValueGetter(valueGetter envValueGetterFunc) *eco {
 	if valueGetter != nil {
-		e.envValueGetter = valueGetter
+		e.SetValueLookup(func(key string) (string, bool) {
+			v := valueGetter(key)
+			return v, v != ""
+		})
 	}
 	return e
 }
 
+// SetValueLookup sets the function for looking up the environment variable
+// values, which reports whether a variable is set like os.LookupEnv.
+// It replaces the sources which are set with SetSources.
+func (e *eco) SetValueLookup(valueLookup envValueLookupFunc) *eco {
+	if valueLookup != nil {
+		e.sources = []Source{&lookupSource{lookup: valueLookup}}
+	}
+	return e
+}
+
+// SetEmptyMode sets how the explicitly empty variables, e.g. "FEATURE_X=",
+// are handled. Default is EmptyAsUnset.
+func (e *eco) SetEmptyMode(mode EmptyMode) *eco {
+	e.emptyMode = mode
+	return e
+}
+
+// SetFileIndirection enables or disables reading the values of all the fields
+// from files, e.g. FOO_FILE=/run/secrets/foo for the variable FOO, when the
+// variables themselves are not set. It can be set per field with the
+// "file" tag as well. Default is false.
+func (e *eco) SetFileIndirection(enabled bool) *eco {
+	e.fileIndirection = enabled
+	return e
+}
+
+// SetStrict enables or disables the strict mode. In strict mode, Unmarshal
+// fails for every variable of the enumerable sources which starts with the
+// prefix but is not read by any field, e.g. a typo like APP_PROT, and suggests
+// the closest variable name. It has no effect if the prefix is not set.
+// Default is false.
+func (e *eco) SetStrict(strict bool) *eco {
+	e.strict = strict
+	return e
+}
+
+// SetExpand enables or disables expanding the variable references in the
+// values and the default values of all the fields, e.g. ${HOME}/data or
+// ${PORT:-8080}. The references are resolved to the sibling fields first,
+// by their resolved or struct relative names, and then to the variables.
+// It can be set per field with the "expand" tag as well. Default is false.
+func (e *eco) SetExpand(enabled bool) *eco {
+	e.expand = enabled
+	return e
+}
+
+// SetLazyStructs enables or disables leaving the nil pointer to struct fields
+// nil unless a variable of their fields is set, e.g. DB_HOST for the field
+// DB *Database, so that the optional structs are not allocated with only
+// their default values. Default is false.
+func (e *eco) SetLazyStructs(enabled bool) *eco {
+	e.lazyStructs = enabled
+	return e
+}
+
+// SetFileSizeLimit sets the maximum size in bytes of the files which are read
+// for the file indirection. Non-positive limits are ignored. Default is 1 MiB.
+func (e *eco) SetFileSizeLimit(limit int64) *eco {
+	if limit > 0 {
+		e.fileSizeLimit = limit
+	}
+	return e
+}
+
+// SetSources sets the ordered sources for the environment variable values.
+// The first source which has a key supplies its value, e.g. with
+// SetSources(EnvSource(), local, dotenv) the environment of the process
+// overrides the ".env.local" file, which overrides the ".env" file.
+// Default is the environment of the process.
+func (e *eco) SetSources(sources ...Source) *eco {
+	var srcs []Source
+	for _, src := range sources {
+		if src != nil {
+			srcs = append(srcs, src)
+		}
+	}
+
+	if len(srcs) > 0 {
+		e.sources = srcs
+	}
+	return e
+}
+
+// Lookup returns the value of the given environment variable
+// and the source which supplies it.
+func (e *eco) Lookup(key string) (value string, source Source, ok bool) {
+	for _, src := range e.sources {
+		if v, ok := src.Lookup(key); ok {
+			return v, src, true
+		}
+	}
+	return "", nil, false
+}
+
+// keys returns the names of the variables of all the enumerable sources.
+func (e *eco) keys() []string {
+	seen := map[string]bool{}
+
+	var keys []string
+	for _, src := range e.sources {
+		es, ok := src.(EnumerableSource)
+		if !ok {
+			continue
+		}
+
+		for _, k := range es.Keys() {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	return keys
+}
+
+// RegisterConverter registers a function for converting the environment
+// variable values to the given type. Registered converters take precedence
+// over all the other conversions, including Decoder and TextUnmarshaler.
+func (e *eco) RegisterConverter(t reflect.Type, converter converterFunc) *eco {
+	if t != nil && converter != nil {
+		e.converters[t] = converter
+	}
+	return e
+}
+
+// RegisterTypeWith registers a typed function for converting the environment
+// variable values to the type T on the given Eco instance.
+func RegisterTypeWith[T any](e *eco, converter func(value string) (T, error)) *eco {
+	if converter == nil {
+		return e
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return e.RegisterConverter(t, func(value string) (interface{}, error) {
+		return converter(value)
+	})
+}
+
 // Unmarshal takes a pointer to a struct and unmarshals the environment variables to the struct.
 func (e *eco) Unmarshal(v interface{}) error {
 	if v == nil {
@@ -106,17 +318,235 @@ func (e *eco) Unmarshal(v interface{}) error {
 		return ErrRequiresNonNilPtr
 	}
 
+	if rt.Elem().Kind() != reflect.Struct {
+		return ErrRequiresStructPtr
+	}
+
+	return e.unmarshal(v, &unmarshalState{})
+}
+
+// Explain is like Unmarshal but returns a report of where the values of the
+// fields come from, e.g. the sources, the default values or the files. The
+// report is returned even if Unmarshal fails, unless v is not a pointer to
+// a struct.
+func (e *eco) Explain(v interface{}) (*Report, error) {
+	rv := reflect.ValueOf(v)
+	if v == nil || rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, ErrRequiresNonNilPtr
+	}
+
+	if rv.Elem().Kind() != reflect.Struct {
+		return nil, ErrRequiresStructPtr
+	}
+
+	st := &unmarshalState{report: &Report{}}
+	return st.report, e.unmarshal(v, st)
+}
+
+// unmarshal binds the environment variables to the given pointer to a struct
+// with the given state, and returns the collected errors.
+func (e *eco) unmarshal(v interface{}, st *unmarshalState) error {
 	var p []string
 	if prefix := e.getPrefix(); prefix != "" {
 		p = append(p, prefix)
 	}
 
-	return e.bindStructValues(v, p...)
+	if err := e.bindStructValues(v, st, reflect.TypeOf(v).Elem().Name(), p...); err != nil {
+		return err
+	}
+
+	if e.strict && len(p) > 0 {
+		e.addUnknownErrors(st, e.envNameTransformer(p, e.envNameSeparator)+e.envNameSeparator)
+	}
+
+	return st.err()
+}
+
+// LoadWith returns a new value of the type T which is populated from the
+// environment variables with the given Eco instance, configured with the
+// given options for this call only. T must be a struct or a pointer to a struct.
+func LoadWith[T any](e *eco, opts ...Option) (T, error) {
+	var v T
+	e = e.with(opts...)
+
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.Kind() == reflect.Ptr {
+		rv.Set(reflect.New(rv.Type().Elem()))
+		return v, e.Unmarshal(rv.Interface())
+	}
+
+	return v, e.Unmarshal(&v)
+}
+
+// MustLoadWith is like LoadWith but panics if the value cannot be loaded.
+// The panic value is an error which lists all the failed fields.
+func MustLoadWith[T any](e *eco, opts ...Option) T {
+	v, err := LoadWith[T](e, opts...)
+	if err != nil {
+		panic(fmt.Errorf("cannot load %s: %w", reflect.TypeOf((*T)(nil)).Elem(), err))
+	}
+
+	return v
+}
+
+// unmarshalState holds the state of a single Unmarshal call.
+type unmarshalState struct {
+	// errs is the list of field errors which occurred while binding
+	// the values, so that all of them can be reported at once.
+	errs []*FieldError
+	// found is the list of the names of the environment variables which
+	// have a value, in the order in which they are read by the fields.
+	found []string
+	// keys is the set of the environment variable names which are read
+	// by the fields, so that the unknown ones can be reported in strict mode.
+	keys map[string]bool
+	// prefixes is the list of the name prefixes whose variables are read
+	// by the map fields, e.g. "LABELS_" for LABELS_TEAM.
+	prefixes []string
+	// report records where the values of the fields come from.
+	// It is nil unless the report is requested with Explain.
+	report *Report
+	// types are the struct types which are being bound, from the root
+	// struct to the current one, for detecting the recursive types.
+	types []reflect.Type
+	// ptrs is the set of the pointers to the structs which are being bound,
+	// for detecting the cyclic values, e.g. n.Next = &n.
+	ptrs map[interface{}]bool
+}
+
+// find marks the given environment variable name as having a value.
+func (st *unmarshalState) find(key string) {
+	st.found = append(st.found, key)
+}
+
+// hasFound reports whether any variable with the given name prefix has been
+// found since the given number of the found variables.
+func (st *unmarshalState) hasFound(since int, prefix string) bool {
+	for _, key := range st.found[since:] {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// addError adds a field error to the state.
+func (st *unmarshalState) addError(err *FieldError) {
+	st.errs = append(st.errs, err)
+}
+
+// isBinding reports whether the given struct type is being bound,
+// which means that binding it again would recurse.
+func (st *unmarshalState) isBinding(t reflect.Type) bool {
+	for _, bt := range st.types {
+		if bt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// reportLen returns the number of the field reports, if the report is requested.
+func (st *unmarshalState) reportLen() int {
+	if st.report == nil {
+		return 0
+	}
+	return len(st.report.Fields)
+}
+
+// record adds the given field report to the report, if it is requested.
+func (st *unmarshalState) record(fr FieldReport) {
+	if st.report != nil {
+		st.report.Fields = append(st.report.Fields, fr)
+	}
+}
+
+// use marks the given environment variable name as read by a field.
+func (st *unmarshalState) use(key string) {
+	if st.keys == nil {
+		st.keys = map[string]bool{}
+	}
+	st.keys[key] = true
+}
+
+// usePrefix marks the variables with the given name prefix as read by a field.
+func (st *unmarshalState) usePrefix(prefix string) {
+	st.prefixes = append(st.prefixes, prefix)
+}
+
+// isUsed reports whether the given environment variable is read by a field.
+func (st *unmarshalState) isUsed(key string) bool {
+	if st.keys[key] {
+		return true
+	}
+
+	for _, prefix := range st.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// suggest returns the name of the variable which is read by a field and
+// is the closest to the given name, or an empty string if none is close.
+func (st *unmarshalState) suggest(key string) string {
+	maxDist := len(key) / 3
+	if maxDist < 1 {
+		maxDist = 1
+	}
+
+	known := make([]string, 0, len(st.keys))
+	for k := range st.keys {
+		known = append(known, k)
+	}
+	sort.Strings(known)
+
+	var suggestion string
+	for _, k := range known {
+		if d := levenshtein(key, k); d <= maxDist {
+			suggestion, maxDist = k, d-1
+		}
+	}
+
+	return suggestion
+}
+
+// err returns the collected field errors as a MultiError,
+// or nil if there is no error.
+func (st *unmarshalState) err() error {
+	if len(st.errs) == 0 {
+		return nil
+	}
+
+	return &MultiError{Errors: st.errs}
 }
 
 // bindStructValues binds the environment variables to the given struct.
-func (e *eco) bindStructValues(s interface{}, envNameParts ...string) error {
+// The fieldPath is the Go path of the struct, e.g. "Config.Sub1", and
+// it is used for reporting the errors.
+func (e *eco) bindStructValues(s interface{}, st *unmarshalState, fieldPath string, envNameParts ...string) error {
 	sr := e.getStructReflection(s)
+	errs := len(st.errs)
+	var x *expander
+
+	// set records the fields which get a value, for the conditional rules
+	set := map[string]bool{}
+
+	st.types = append(st.types, sr.Type())
+	defer func() { st.types = st.types[:len(st.types)-1] }()
+
+	if st.ptrs == nil {
+		st.ptrs = map[interface{}]bool{}
+	}
+	st.ptrs[s] = true
+	defer delete(st.ptrs, s)
+
+	// let the struct set the defaults which cannot be expressed as tags
+	if d, ok := s.(Defaulter); ok {
+		d.SetDefaults()
+	}
 
 	for i := 0; i < sr.Type().NumField(); i++ {
 		field := sr.Field(i)
@@ -128,46 +558,186 @@ func (e *eco) bindStructValues(s interface{}, envNameParts ...string) error {
 		}
 
 		tags := typeField.Tag
+		path := joinFieldPath(fieldPath, typeField.Name)
 		isPtr := field.Type().Kind() == reflect.Ptr
-		isStruct := typeField.Type.Kind() == reflect.Struct
+		isStruct := e.isNestedStruct(typeField.Type)
+		secret := e.isSecret(typeField)
+
+		p, envKey, envTagOpts := e.getFieldEnvName(typeField, envNameParts)
+		found := len(st.found)
+
+		// if field is a slice of structs, bind the indexed variables
+		if e.isStructSlice(typeField.Type) {
+			n, err := e.bindStructSlice(field, st, path, p...)
+			if err != nil {
+				return err
+			}
+			set[typeField.Name] = n > 0
+
+			if n == 0 && e.isRequired(tags, envTagOpts) {
+				st.addError(&FieldError{
+					Field: path,
+					Key:   envKey,
+					Type:  typeField.Type,
+					Err:   ErrRequired,
+				})
+			}
+
+			continue
+		}
 
-		envTagValue, ok := tags.Lookup(e.tagNameEnv)
-		if !ok || envTagValue == "" {
-			// If field "env" tag is not provided, get tag from struct field name
-			envTagValue = typeField.Name
+		// get value from env
+		envVal, src, envSet := e.Lookup(envKey)
+		if !isStruct {
+			st.use(envKey)
 		}
 
-		envTagValue = toSnakeCase(envTagValue)
+		// if value is unset and file indirection is enabled for the field,
+		// read it from the file whose path is the value of e.g. FOO_FILE
+		var file string
+		if envVal == "" && (!envSet || e.emptyMode == EmptyAsUnset) && !isStruct && e.isFileEnabled(tags) {
+			fileKey := e.envNameTransformer(append(append([]string{}, p...), "file"), e.envNameSeparator)
+			st.use(fileKey)
 
-		p := envNameParts
+			val, filePath, fileSrc, err := e.readValueFile(fileKey)
+			if err != nil {
+				st.addError(&FieldError{
+					Field: path,
+					Key:   fileKey,
+					Type:  typeField.Type,
+					Err:   err,
+				})
+				continue
+			}
 
-		// if tag value is "-", skip this field
-		// when looking for the environment variable name
-		skip := envTagValue == e.tagSkipIdentifier
-		if !skip {
-			p = append(p, envTagValue)
+			if filePath != "" {
+				envVal, envSet, src, file = val, true, fileSrc, filePath
+			}
 		}
 
-		// sanitize env variable name using the envNameFunc
-		envKey := e.envNameTransformer(p, e.envNameSeparator)
+		if envVal != "" {
+			st.find(envKey)
+		}
 
-		var envVal string
-		var err error
+		// if the variable is set but empty, handle it according
+		// to the empty mode unless it is treated as unset
+		if envSet && envVal == "" && e.emptyMode != EmptyAsUnset && !isStruct {
+			st.find(envKey)
+			set[typeField.Name] = true
+			if e.emptyMode == EmptyClearsValue {
+				field.Set(emptyValue(field.Type()))
+				e.validateField(st, typeField, field, path, envKey, "", secret)
+			} else {
+				e.validateKeptField(st, typeField, field, path, envKey)
+			}
 
-		// get value from env
-		envVal = e.envValueGetter(envKey)
+			st.record(FieldReport{
+				Field:  path,
+				Key:    envKey,
+				Source: src.Name(),
+				File:   file,
+				Kept:   e.emptyMode == EmptySkipsDefault,
+			})
+			continue
+		}
+
+		// if value is empty and the field is a map, collect its
+		// entries from the prefixed variables, e.g. LABELS_TEAM=core
+		var entries map[string]string
+		var entrySources []string
+		if envVal == "" && isMapType(typeField.Type) {
+			entries, entrySources = e.getPrefixedValues(envKey)
+			for range entries {
+				st.find(envKey)
+			}
+			st.usePrefix(envKey + e.envNameSeparator)
+		}
 
 		// if value is empty, get default value from tag
-		if envVal == "" {
+		var usedDefault bool
+		if envVal == "" && len(entries) == 0 {
 			defaultValue, ok := tags.Lookup(e.tagNameDefault)
 			if ok {
-				envVal = defaultValue
+				envVal, usedDefault = defaultValue, defaultValue != ""
+			}
+		}
+
+		// expand the variable references in the value, e.g. ${HOME}/data,
+		// unless it is read from a file. The value is treated as secret
+		// if it refers to a secret field.
+		if envVal != "" && file == "" && !isStruct && e.isExpandEnabled(tags) {
+			if x == nil {
+				x = e.newExpander(sr.Type(), envNameParts)
+			}
+
+			expanded, usedSecret, err := x.expandField(envKey, envVal)
+			secret = secret || usedSecret
+			if err != nil {
+				st.addError(e.newValueError(typeField, path, envKey, envVal, secret, err))
+				continue
+			}
+			envVal = expanded
+		}
+
+		// record where the value of the field comes from
+		if st.report != nil && !isStruct {
+			fr := FieldReport{
+				Field:   path,
+				Key:     envKey,
+				File:    file,
+				Default: usedDefault,
+				Kept:    envVal == "" && len(entries) == 0,
+				Value:   envVal,
+			}
+
+			switch {
+			case len(entries) > 0:
+				fr.Source = strings.Join(entrySources, ",")
+				fr.Value = e.formatEntries(entries, tags)
+			case src != nil && !usedDefault:
+				fr.Source = src.Name()
+			}
+
+			if secret {
+				fr.Value = redact(fr.Value)
 			}
+
+			st.record(fr)
+		}
+
+		// if value is still empty and the field is required, collect it
+		// so that all missing variables can be reported at once
+		if envVal == "" && len(entries) == 0 && !isStruct && e.isRequired(tags, envTagOpts) {
+			st.addError(&FieldError{
+				Field: path,
+				Key:   envKey,
+				Type:  typeField.Type,
+				Err:   ErrRequired,
+			})
+			continue
+		}
+
+		// if value is still empty, the field keeps its current value,
+		// which must still satisfy the "notempty" tag
+		if envVal == "" && len(entries) == 0 && !isStruct {
+			e.validateKeptField(st, typeField, field, path, envKey)
+			continue
+		}
+
+		if !isStruct {
+			set[typeField.Name] = true
 		}
 
 		// if field is a pointer, create
 		if isPtr {
-			if typeField.Type.Elem().Kind() != reflect.Struct && envVal == "" {
+			// if field is a nil struct pointer, bind a new struct, which is
+			// kept only if any of its variables is set in the lazy mode
+			if isStruct && field.IsNil() {
+				if err := e.bindStructPtr(field, st, path, p...); err != nil {
+					return err
+				}
+				set[typeField.Name] = len(st.found) > found
+
 				continue
 			}
 
@@ -176,45 +746,366 @@ func (e *eco) bindStructValues(s interface{}, envNameParts ...string) error {
 				field.Set(reflect.New(field.Type().Elem()))
 			}
 
-			// check whether the field element kind
-			// is a struct, then bind its values
-			if field.Elem().Kind() == reflect.Struct {
-				if err := e.bindStructValues(field.Interface(), p...); err != nil {
+			// check whether the field element
+			// is a struct, then bind its values, unless
+			// it is being bound already, e.g. n.Next = &n
+			if isStruct {
+				if st.ptrs[field.Interface()] {
+					st.addError(&FieldError{
+						Field: path,
+						Key:   envKey,
+						Type:  typeField.Type,
+						Err:   fmt.Errorf("%w: %s", ErrTypeCycle, field.Type().Elem()),
+					})
+					continue
+				}
+
+				if err := e.bindStructValues(field.Interface(), st, path, p...); err != nil {
 					return err
 				}
+				set[typeField.Name] = len(st.found) > found
 
 				continue
 			}
 		} else if isStruct { // if field is a struct, bind it
-			if err := e.bindStructValues(field.Addr().Interface(), p...); err != nil {
+			if err := e.bindStructValues(field.Addr().Interface(), st, path, p...); err != nil {
 				return err
 			}
+			set[typeField.Name] = len(st.found) > found
 
 			continue
 		}
 
-		// if value is empty, skip binding
-		if envVal == "" {
-			continue
+		// convert string value which comes from env to the type of the field
+		var val reflect.Value
+		var err error
+		if len(entries) > 0 {
+			val, err = e.convertEntriesToMap(derefType(typeField.Type), entries, tags)
+		} else {
+			val, err = e.convertStrToFieldVal(sr, i, envVal)
 		}
 
-		// convert string value which comes from env to the type of the field
-		val, err := e.convertStrToFieldVal(sr, i, envVal)
 		if err != nil {
-			return errors.New(err.Error() + ": " + envKey)
+			st.addError(e.newValueError(typeField, path, envKey, envVal, secret, err))
+			continue
 		}
 
-		// set field value
-		if isPtr {
+		// set field value, through the pointer unless
+		// the value is converted to the pointer type itself
+		if isPtr && val.Type() != field.Type() {
 			field.Elem().Set(val)
 		} else {
 			field.Set(val)
 		}
+
+		e.validateField(st, typeField, field, path, envKey, envVal, secret)
+	}
+
+	// check the conditional rules after all the fields are bound
+	e.checkFieldRules(sr, st, errs, set, fieldPath, envNameParts)
+
+	// let the struct validate itself, e.g. for the cross-field rules,
+	// if all of its fields, including the nested ones, are bound
+	if v, ok := s.(Validator); ok && len(st.errs) == errs {
+		if err := v.Validate(); err != nil {
+			st.addError(&FieldError{
+				Field: fieldPath,
+				Key:   e.envNameTransformer(envNameParts, e.envNameSeparator),
+				Type:  sr.Type(),
+				Err:   err,
+			})
+		}
 	}
 
 	return nil
 }
 
+// validateField checks the value of the given field against the validation
+// tags, and adds an error for each violation to the state, which is redacted
+// if the value is secret.
+func (e *eco) validateField(st *unmarshalState, typeField reflect.StructField, field reflect.Value, path, key, value string, secret bool) {
+	for _, err := range e.validate(field, typeField.Tag) {
+		st.addError(e.newValueError(typeField, path, key, value, secret, err))
+	}
+}
+
+// validateKeptField checks the current value of the given field, to which
+// no value is bound, against the "notempty" tag, and adds an error for the
+// violation to the state. The other validation tags are not checked, since
+// the current value is not read from the environment.
+func (e *eco) validateKeptField(st *unmarshalState, typeField reflect.StructField, field reflect.Value, path, key string) {
+	if err := e.validateNotEmpty(field, typeField.Tag); err != nil {
+		st.addError(e.newValueError(typeField, path, key, "", e.isSecret(typeField), err))
+	}
+}
+
+// newValueError returns a FieldError for the given value of the given field.
+// If the value is secret, e.g. of a secret field or expanded from one, it is
+// redacted, and the message of the cause is hidden, since it may quote any
+// part of the value.
+func (e *eco) newValueError(typeField reflect.StructField, path, key, value string, secret bool, err error) *FieldError {
+	fe := &FieldError{
+		Field: path,
+		Key:   key,
+		Type:  typeField.Type,
+		Value: value,
+		Err:   err,
+	}
+
+	if !secret {
+		return fe
+	}
+
+	fe.Value = redact(value)
+	fe.Err = newRedactedError(err)
+
+	return fe
+}
+
+// bindStructPtr binds the environment variables to a new struct and sets
+// the given nil pointer field to it. In the lazy mode, the field is left nil,
+// and the errors and the reports of the struct are discarded, unless any of
+// its variables is set. The recursive types are bound again only in the lazy
+// mode and if any variable of the enumerable sources starts with the name
+// prefix of the field, and they fail with ErrTypeCycle otherwise.
+func (e *eco) bindStructPtr(field reflect.Value, st *unmarshalState, fieldPath string, envNameParts ...string) error {
+	structType := field.Type().Elem()
+	key := e.envNameTransformer(envNameParts, e.envNameSeparator)
+
+	if st.isBinding(structType) {
+		if !e.lazyStructs {
+			st.addError(&FieldError{
+				Field: fieldPath,
+				Key:   key,
+				Type:  field.Type(),
+				Err:   fmt.Errorf("%w: %s", ErrTypeCycle, structType),
+			})
+			return nil
+		}
+
+		if !e.hasPrefixedValues(key + e.envNameSeparator) {
+			return nil
+		}
+	}
+
+	found, errs, fields := len(st.found), len(st.errs), st.reportLen()
+
+	elem := reflect.New(structType)
+	if err := e.bindStructValues(elem.Interface(), st, fieldPath, envNameParts...); err != nil {
+		return err
+	}
+
+	if e.lazyStructs && len(st.found) == found {
+		st.errs = st.errs[:errs]
+		if st.report != nil {
+			st.report.Fields = st.report.Fields[:fields]
+		}
+		return nil
+	}
+
+	field.Set(elem)
+
+	return nil
+}
+
+// bindStructSlice binds the indexed environment variables, e.g. UPSTREAMS_0_HOST
+// and UPSTREAMS_1_HOST, to the given slice of structs or pointers to structs.
+// The indices are discovered from zero until there is no variable for an index.
+// The field is left untouched if there is no variable for the first index, and
+// the number of the bound elements is returned.
+func (e *eco) bindStructSlice(field reflect.Value, st *unmarshalState, fieldPath string, envNameParts ...string) (int, error) {
+	sliceType := field.Type()
+	isPtr := sliceType.Elem().Kind() == reflect.Ptr
+	structType := derefType(sliceType.Elem())
+
+	out := reflect.MakeSlice(sliceType, 0, 0)
+	for i := 0; ; i++ {
+		found, errs, fields := len(st.found), len(st.errs), st.reportLen()
+
+		p := append(append([]string{}, envNameParts...), strconv.Itoa(i))
+		path := fmt.Sprintf("%s[%d]", fieldPath, i)
+		prefix := e.envNameTransformer(p, e.envNameSeparator) + e.envNameSeparator
+
+		// bind the recursive types again only if any variable of the element
+		// is set, since the element of every index is bound to discover it
+		if st.isBinding(structType) && !e.hasPrefixedValues(prefix) {
+			break
+		}
+
+		elem := reflect.New(structType)
+		if err := e.bindStructValues(elem.Interface(), st, path, p...); err != nil {
+			return 0, err
+		}
+
+		// stop at the first index without any variable, and discard the errors
+		// and the reports of that element, such as the missing required fields.
+		// Only the variables of the index count, since the "noprefix" fields
+		// of every element read the same variables.
+		if !st.hasFound(found, prefix) {
+			st.errs = st.errs[:errs]
+			if st.report != nil {
+				st.report.Fields = st.report.Fields[:fields]
+			}
+			break
+		}
+
+		if isPtr {
+			out = reflect.Append(out, elem)
+		} else {
+			out = reflect.Append(out, elem.Elem())
+		}
+	}
+
+	if out.Len() > 0 {
+		field.Set(out)
+	}
+
+	return out.Len(), nil
+}
+
+// addUnknownErrors adds an error for every variable of the enumerable sources
+// whose name starts with the given prefix but which is not read by any field.
+func (e *eco) addUnknownErrors(st *unmarshalState, prefix string) {
+	keys := e.keys()
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if !strings.HasPrefix(k, prefix) || st.isUsed(k) {
+			continue
+		}
+
+		err := ErrUnknownVariable
+		if suggestion := st.suggest(k); suggestion != "" {
+			err = fmt.Errorf("%w, did you mean %s?", ErrUnknownVariable, suggestion)
+		}
+
+		st.addError(&FieldError{Key: k, Err: err})
+	}
+}
+
+// getPrefixedValues returns the values of the variables of the enumerable
+// sources whose names start with the given key and the name separator. The returned map
+// is keyed by the lower-cased rest of the names, e.g. "team" for LABELS_TEAM.
+// The names of the sources which supply the values are returned as well.
+func (e *eco) getPrefixedValues(key string) (map[string]string, []string) {
+	prefix := key + e.envNameSeparator
+	values := map[string]string{}
+	seen := map[string]bool{}
+
+	var sources []string
+	for _, k := range e.keys() {
+		if !strings.HasPrefix(k, prefix) || len(k) == len(prefix) {
+			continue
+		}
+
+		if v, src, _ := e.Lookup(k); v != "" {
+			values[strings.ToLower(k[len(prefix):])] = v
+			if !seen[src.Name()] {
+				seen[src.Name()] = true
+				sources = append(sources, src.Name())
+			}
+		}
+	}
+
+	return values, sources
+}
+
+// hasPrefixedValues reports whether any variable of the enumerable
+// sources whose name starts with the given prefix has a value.
+func (e *eco) hasPrefixedValues(prefix string) bool {
+	for _, k := range e.keys() {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		if v, _, _ := e.Lookup(k); v != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isFileEnabled reports whether the file indirection is enabled for a field,
+// either with the "file" tag or globally with SetFileIndirection.
+func (e *eco) isFileEnabled(tags reflect.StructTag) bool {
+	if enabled, err := strconv.ParseBool(tags.Get(e.tagNameFile)); err == nil {
+		return enabled
+	}
+
+	return e.fileIndirection
+}
+
+// readValueFile reads the file whose path is the value of the given variable
+// and returns its contents without the trailing newlines, with the path and
+// the source of the variable. The path is empty if the variable is not set.
+func (e *eco) readValueFile(key string) (value, path string, source Source, err error) {
+	path, source, _ = e.Lookup(key)
+	if path == "" {
+		return "", "", nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(io.LimitReader(f, e.fileSizeLimit+1))
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if int64(len(b)) > e.fileSizeLimit {
+		return "", "", nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrFileTooLarge, path, e.fileSizeLimit)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), path, source, nil
+}
+
+// getFieldEnvName returns the environment variable name parts and the
+// environment variable name of the given struct field, whose parent has
+// the given name parts. The options of the "env" tag are returned as well.
+// With the "noprefix" option, e.g. `env:"PORT,noprefix"`, the parent name
+// parts, including the prefix, are dropped and the name is used as is.
+func (e *eco) getFieldEnvName(typeField reflect.StructField, envNameParts []string) (p []string, envKey string, envTagOpts []string) {
+	envTagValue, envTagOpts := parseTag(typeField.Tag.Get(e.tagNameEnv))
+	if envTagValue == "" {
+		// If field "env" tag is not provided, get tag from struct field name
+		envTagValue = typeField.Name
+	}
+
+	envTagValue = toSnakeCase(envTagValue)
+
+	p = envNameParts
+	if hasOption(envTagOpts, "noprefix") {
+		p = nil
+	}
+
+	// if tag value is "-", skip this field
+	// when looking for the environment variable name
+	skip := envTagValue == e.tagSkipIdentifier
+	if !skip {
+		p = append(p, envTagValue)
+	}
+
+	// sanitize env variable name using the envNameFunc
+	envKey = e.envNameTransformer(p, e.envNameSeparator)
+
+	return p, envKey, envTagOpts
+}
+
+// isRequired reports whether a field is marked as required, either with
+// the "required" tag or with the "required" option of the "env" tag.
+func (e *eco) isRequired(tags reflect.StructTag, envTagOpts []string) bool {
+	if hasOption(envTagOpts, "required") {
+		return true
+	}
+
+	required, _ := strconv.ParseBool(tags.Get(e.tagNameRequired))
+	return required
+}
+
 // getStructReflection returns the reflection of the given struct.
 func (e *eco) getStructReflection(s interface{}) reflect.Value {
 	sv := reflect.ValueOf(s)
@@ -226,112 +1117,281 @@ func (e *eco) getStructReflection(s interface{}) reflect.Value {
 	return sv
 }
 
+// isNestedStruct reports whether the given type is a struct or a pointer to
+// a struct whose fields should be bound one by one. Struct types which are
+// converted from a single value, such as time.Time, are not nested structs.
+func (e *eco) isNestedStruct(t reflect.Type) bool {
+	if e.isDecodable(t) {
+		return false
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && !e.isDecodable(t)
+}
+
+// isStructSlice reports whether the given type is a slice of structs
+// or pointers to structs whose fields should be bound one by one.
+func (e *eco) isStructSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && !e.isDecodable(t) && e.isNestedStruct(t.Elem())
+}
+
+// isDecodable reports whether the given type is converted from a single
+// value with a dedicated decoder instead of the built-in kind conversions.
+func (e *eco) isDecodable(t reflect.Type) bool {
+	if _, ok := e.converters[t]; ok {
+		return true
+	}
+
+	switch t {
+	case durationType, timeType:
+		return true
+	}
+
+	ptr := reflect.PtrTo(t)
+	return ptr.Implements(decoderType) || ptr.Implements(textUnmarshalerType)
+}
+
 // convertStrToFieldVal converts the given string value to the type of the field.
-func (e *eco) convertStrToFieldVal(ref reflect.Value, index int, val interface{}) (out reflect.Value, err error) {
-	field := ref.Field(index)
+func (e *eco) convertStrToFieldVal(ref reflect.Value, index int, val string) (reflect.Value, error) {
 	typeField := ref.Type().Field(index)
-	isPtr := typeField.Type.Kind() == reflect.Ptr
 
-	kind := field.Kind()
-	if isPtr {
-		kind = field.Elem().Kind()
+	// pointer fields are converted to their element type unless
+	// there is a converter registered for the pointer type itself
+	t := typeField.Type
+	if t.Kind() == reflect.Ptr && e.converters[t] == nil {
+		t = t.Elem()
+	}
+
+	return e.convertStrToType(t, val, typeField.Tag)
+}
+
+// convertStrToType converts the given string value to the given type.
+// The tags of the field are used for the type specific options, such as
+// the layout of the time values.
+func (e *eco) convertStrToType(t reflect.Type, val string, tags reflect.StructTag) (reflect.Value, error) {
+	var out interface{}
+	var err error
+
+	if converter, ok := e.converters[t]; ok {
+		return e.convertWithConverter(t, val, converter)
 	}
 
-	switch kind {
+	// pointers are allocated and their elements are converted,
+	// which is the case for the slices of pointers, e.g. []*Level
+	if t.Kind() == reflect.Ptr {
+		v, err := e.convertStrToType(t.Elem(), val, tags)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(v)
+		return ptr, nil
+	}
+
+	// types implementing Decoder take precedence over all the other conversions
+	if reflect.PtrTo(t).Implements(decoderType) {
+		ptr := reflect.New(t)
+		err = ptr.Interface().(Decoder).DecodeEnv(val)
+		return ptr.Elem(), err
+	}
+
+	switch t {
+	case durationType:
+		out, err = time.ParseDuration(val)
+		return reflect.ValueOf(out), err
+	case timeType:
+		layout := getTagOrDefault(tags, e.tagNameLayout, defaultTimeLayout)
+		out, err = time.Parse(layout, val)
+		return reflect.ValueOf(out), err
+	}
+
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		ptr := reflect.New(t)
+		err = ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+		return ptr.Elem(), err
+	}
+
+	switch t.Kind() {
 	case reflect.String:
-		val = val.(string)
+		out = val
 	case reflect.Int:
-		val, err = strconv.Atoi(val.(string))
+		out, err = strconv.Atoi(val)
 	case reflect.Uint:
-		val, err = strconv.ParseUint(val.(string), 10, 0)
-		val = uint(val.(uint64))
+		out, err = strconv.ParseUint(val, 10, 0)
 	case reflect.Int64:
-		val, err = strconv.ParseInt(val.(string), 10, 64)
+		out, err = strconv.ParseInt(val, 10, 64)
 	case reflect.Uint64:
-		val, err = strconv.ParseUint(val.(string), 10, 64)
+		out, err = strconv.ParseUint(val, 10, 64)
 	case reflect.Int32:
-		val, err = strconv.ParseInt(val.(string), 10, 32)
-		val = int32(val.(int64))
+		out, err = strconv.ParseInt(val, 10, 32)
 	case reflect.Uint32:
-		val, err = strconv.ParseUint(val.(string), 10, 32)
-		val = uint32(val.(uint64))
+		out, err = strconv.ParseUint(val, 10, 32)
 	case reflect.Int16:
-		val, err = strconv.ParseInt(val.(string), 10, 16)
-		val = int16(val.(int64))
+		out, err = strconv.ParseInt(val, 10, 16)
 	case reflect.Uint16:
-		val, err = strconv.ParseUint(val.(string), 10, 16)
-		val = uint16(val.(uint64))
+		out, err = strconv.ParseUint(val, 10, 16)
 	case reflect.Int8:
-		val, err = strconv.ParseInt(val.(string), 10, 8)
-		val = int8(val.(int64))
+		out, err = strconv.ParseInt(val, 10, 8)
 	case reflect.Uint8:
-		val, err = strconv.ParseUint(val.(string), 10, 8)
-		val = uint8(val.(uint64))
+		out, err = strconv.ParseUint(val, 10, 8)
 	case reflect.Float32:
-		val, err = strconv.ParseFloat(val.(string), 32)
-		val = float32(val.(float64))
+		out, err = strconv.ParseFloat(val, 32)
 	case reflect.Float64:
-		val, err = strconv.ParseFloat(val.(string), 64)
+		out, err = strconv.ParseFloat(val, 64)
 	case reflect.Bool:
-		val, err = strconv.ParseBool(val.(string))
+		out, err = strconv.ParseBool(val)
 	case reflect.Slice:
-		switch typeField.Type.Elem().Kind() {
-		case reflect.String:
-			val = strings.Split(val.(string), e.sliceSeparator)
-			vals := make([]string, len(val.([]string)))
-			for i, v := range val.([]string) {
-				vals[i] = strings.TrimSpace(v)
-			}
-			val = vals
-		case reflect.Int:
-			val = strings.Split(val.(string), e.sliceSeparator)
-			var vals []int
-			for _, v := range val.([]string) {
-				i, err := strconv.Atoi(strings.TrimSpace(v))
-				if err != nil {
-					return reflect.ValueOf(nil), err
-				}
-				vals = append(vals, i)
-			}
-			val = vals
-		case reflect.Int64:
-			val = strings.Split(val.(string), e.sliceSeparator)
-			var vals []int64
-			for _, v := range val.([]string) {
-				i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
-				if err != nil {
-					return reflect.ValueOf(nil), err
-				}
-				vals = append(vals, i)
-			}
-			val = vals
-		case reflect.Float32:
-			val = strings.Split(val.(string), e.sliceSeparator)
-			var vals []float32
-			for _, v := range val.([]string) {
-				f, err := strconv.ParseFloat(strings.TrimSpace(v), 32)
-				if err != nil {
-					return reflect.ValueOf(nil), err
+		return e.convertStrToSlice(t, val, tags)
+	case reflect.Map:
+		return e.convertStrToMap(t, val, tags)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type: %s", t.Kind())
+	}
 
-				}
-				vals = append(vals, float32(f))
-			}
-			val = vals
-		case reflect.Float64:
-			val = strings.Split(val.(string), e.sliceSeparator)
-			var vals []float64
-			for _, v := range val.([]string) {
-				f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
-				if err != nil {
-					return reflect.ValueOf(nil), err
-				}
-				vals = append(vals, float64(f))
-			}
-			val = vals
-		default:
-			return reflect.ValueOf(nil), fmt.Errorf("unsupported slice type: %s", typeField.Type.Elem().Kind())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	// convert the parsed value to the exact type of the field,
+	// e.g. int64 to int32 or string to a named string type
+	return reflect.ValueOf(out).Convert(t), nil
+}
+
+// convertWithConverter converts the given string value with the given
+// registered converter and checks the result against the given type.
+func (e *eco) convertWithConverter(t reflect.Type, val string, converter converterFunc) (reflect.Value, error) {
+	out, err := converter(val)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	rv := reflect.ValueOf(out)
+	switch {
+	case !rv.IsValid():
+		return reflect.Zero(t), nil
+	case rv.Type().AssignableTo(t):
+		return rv, nil
+	case rv.Type().ConvertibleTo(t):
+		return rv.Convert(t), nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("converter for %s returned %s", t, rv.Type())
+}
+
+// convertStrToSlice splits the given string value by the slice separator
+// and converts each of the items to the element type of the given slice type.
+func (e *eco) convertStrToSlice(t reflect.Type, val string, tags reflect.StructTag) (reflect.Value, error) {
+	if !e.isSupportedSliceElem(t.Elem()) {
+		return reflect.Value{}, fmt.Errorf("unsupported slice type: %s", t.Elem().Kind())
+	}
+
+	items := strings.Split(val, getTagOrDefault(tags, e.tagNameSeparator, e.sliceSeparator))
+	out := reflect.MakeSlice(t, 0, len(items))
+	for _, item := range items {
+		v, err := e.convertStrToType(t.Elem(), strings.TrimSpace(item), tags)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out = reflect.Append(out, v)
+	}
+
+	return out, nil
+}
+
+// convertStrToMap splits the given string value into its pairs, e.g.
+// "k1:v1,k2:v2", and converts them to the key and element types of
+// the given map type.
+func (e *eco) convertStrToMap(t reflect.Type, val string, tags reflect.StructTag) (reflect.Value, error) {
+	pairSep := getTagOrDefault(tags, e.tagNameSeparator, e.mapPairSeparator)
+	kvSep := getTagOrDefault(tags, e.tagNameKeyValueSep, e.mapKeyValueSeparator)
+
+	entries := map[string]string{}
+	for _, pair := range strings.Split(val, pairSep) {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(pair, kvSep)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("invalid map item %q: missing separator %q", pair, kvSep)
+		}
+
+		entries[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	return e.convertEntriesToMap(t, entries, tags)
+}
+
+// convertEntriesToMap converts the given string entries to
+// the key and element types of the given map type.
+func (e *eco) convertEntriesToMap(t reflect.Type, entries map[string]string, tags reflect.StructTag) (reflect.Value, error) {
+	if !e.isSupportedMapElem(t.Key()) || !e.isSupportedMapElem(t.Elem()) {
+		return reflect.Value{}, fmt.Errorf("unsupported map type: %s", t)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := reflect.MakeMapWithSize(t, len(entries))
+	for _, k := range keys {
+		kv, err := e.convertStrToType(t.Key(), k, tags)
+		if err != nil {
+			return reflect.Value{}, err
 		}
+
+		vv, err := e.convertStrToType(t.Elem(), entries[k], tags)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		out.SetMapIndex(kv, vv)
+	}
+
+	return out, nil
+}
+
+// isSupportedMapElem reports whether the given type can be used
+// as the key or the element type of a map field.
+func (e *eco) isSupportedMapElem(t reflect.Type) bool {
+	if e.isDecodable(t) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Ptr:
+		return e.isSupportedMapElem(t.Elem())
+	}
+
+	return false
+}
+
+// isSupportedSliceElem reports whether the given type can be used
+// as the element type of a slice field.
+func (e *eco) isSupportedSliceElem(t reflect.Type) bool {
+	if e.isDecodable(t) {
+		return true
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return e.isSupportedSliceElem(t.Elem())
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int64, reflect.Float32, reflect.Float64:
+		return true
 	}
 
-	return reflect.ValueOf(val), err
+	return false
 }
@@ -1,9 +1,16 @@
 package eco
 
 import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 type SampleStruct1 struct {
@@ -71,6 +78,52 @@ type SampleComplexStruct4_Sub_Sub struct {
 	I64 int64
 }
 
+type SampleLevel int
+
+func (l *SampleLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "warn":
+		*l = 2
+	default:
+		return errors.New("unknown level: " + string(text))
+	}
+	return nil
+}
+
+type SampleID struct {
+	Prefix string
+	Number int
+}
+
+func (id *SampleID) UnmarshalText(text []byte) error {
+	parts := strings.SplitN(string(text), "-", 2)
+	if len(parts) != 2 {
+		return errors.New("invalid id: " + string(text))
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return err
+	}
+	id.Prefix, id.Number = parts[0], n
+	return nil
+}
+
+type SampleDecoder string
+
+func (d *SampleDecoder) DecodeEnv(value string) error {
+	*d = SampleDecoder("decoded:" + value)
+	return nil
+}
+
+// UnmarshalText is never used, since Decoder takes precedence.
+func (d *SampleDecoder) UnmarshalText(text []byte) error {
+	return errors.New("should not be called")
+}
+
 type SampleArrayStruct struct {
 	Foo []string `default:"foo,bar,baz"`
 }
@@ -516,6 +569,11 @@ func TestEco_Unmarshal(t *testing.T) {
 			args:    ss2,
 			wantErr: true,
 		},
+		{
+			name:    "should error if argument is not a pointer to a struct",
+			args:    &sStr,
+			wantErr: true,
+		},
 		{
 			name: "should error if sub struct has any error",
 			args: &SampleComplexStruct3{},
@@ -1871,7 +1929,10 @@ func TestEcoUnmarshal_convertStrToFieldVal_Bool(t *testing.T) {
 		{
 			name: "should error when invalid value with env",
 			args: &Struct{},
-			want: &Struct{},
+			want: &Struct{
+				FieldDef:        true,
+				FieldNumericDef: true,
+			},
 			envs: map[string]string{
 				"FIELD_ENV": "aa",
 			},
@@ -2314,3 +2375,1889 @@ func TestEcoUnmarshal_convertStrToFieldVal_SliceUnsupportedType(t *testing.T) {
 		})
 	}
 }
+
+func TestEco_Unmarshal_Required(t *testing.T) {
+	type Struct struct {
+		DatabaseURL string `env:"DB_URL,required"`
+		Port        int    `required:"true"`
+		Host        string `required:"true" default:"localhost"`
+		Optional    *string
+		Sub         struct {
+			Name string `required:"true"`
+		}
+	}
+
+	tests := []struct {
+		name        string
+		envs        map[string]string
+		args        interface{}
+		want        interface{}
+		wantErr     bool
+		wantMissing []string
+	}{
+		{
+			name: "should bind when all required fields are set",
+			args: &Struct{},
+			envs: map[string]string{
+				"DB_URL":   "postgres://localhost",
+				"PORT":     "8080",
+				"SUB_NAME": "sub",
+			},
+			want: &Struct{
+				DatabaseURL: "postgres://localhost",
+				Port:        8080,
+				Host:        "localhost",
+				Sub: struct {
+					Name string `required:"true"`
+				}{
+					Name: "sub",
+				},
+			},
+		},
+		{
+			name:        "should report every missing required field",
+			args:        &Struct{},
+			wantErr:     true,
+			wantMissing: []string{"DB_URL", "PORT", "SUB_NAME"},
+		},
+		{
+			name: "should report only missing required fields",
+			args: &Struct{},
+			envs: map[string]string{
+				"PORT": "8080",
+			},
+			wantErr:     true,
+			wantMissing: []string{"DB_URL", "SUB_NAME"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			err := e.Unmarshal(m)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrRequired) {
+					t.Errorf("Eco.Unmarshal() error = %v, want %v", err, ErrRequired)
+				}
+				for _, key := range tt.wantMissing {
+					if !strings.Contains(err.Error(), key) {
+						t.Errorf("Eco.Unmarshal() error = %v, want to contain %v", err, key)
+					}
+				}
+				return
+			}
+
+			if tt.want != nil && !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %v, want %v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_Unmarshal_FieldError(t *testing.T) {
+	type Struct struct {
+		Foo  int
+		Bar  string `required:"true"`
+		Sub1 SampleComplexStruct_Sub
+	}
+
+	tests := []struct {
+		name       string
+		envs       map[string]string
+		wantFields []string
+		wantKeys   []string
+	}{
+		{
+			name: "should collect all field errors",
+			envs: map[string]string{
+				"FOO":           "foo",
+				"SUB1_SUB1_I64": "i64",
+			},
+			wantFields: []string{"Struct.Foo", "Struct.Bar", "Struct.Sub1.Sub1.I64"},
+			wantKeys:   []string{"FOO", "BAR", "SUB1_SUB1_I64"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			err := e.Unmarshal(&Struct{})
+
+			var merr *MultiError
+			if !errors.As(err, &merr) {
+				t.Fatalf("Eco.Unmarshal() error = %v, want *MultiError", err)
+			}
+
+			if len(merr.Errors) != len(tt.wantFields) {
+				t.Fatalf("Eco.Unmarshal() errors = %v, want %d errors", merr.Errors, len(tt.wantFields))
+			}
+
+			for i, ferr := range merr.Errors {
+				if ferr.Field != tt.wantFields[i] {
+					t.Errorf("FieldError.Field = %v, want %v", ferr.Field, tt.wantFields[i])
+				}
+				if ferr.Key != tt.wantKeys[i] {
+					t.Errorf("FieldError.Key = %v, want %v", ferr.Key, tt.wantKeys[i])
+				}
+			}
+
+			var numErr *strconv.NumError
+			if !errors.As(err, &numErr) {
+				t.Errorf("Eco.Unmarshal() error = %v, want to wrap *strconv.NumError", err)
+			}
+
+			var ferr *FieldError
+			if !errors.As(err, &ferr) || ferr.Value != "foo" || ferr.Type != reflect.TypeOf(0) {
+				t.Errorf("Eco.Unmarshal() error = %#+v, want first *FieldError", ferr)
+			}
+		})
+	}
+}
+
+func TestEcoUnmarshal_convertStrToFieldVal_Duration(t *testing.T) {
+	d := time.Minute
+
+	type Struct struct {
+		FieldBlank      time.Duration
+		FieldEnv        time.Duration
+		FieldPtr        *time.Duration
+		FieldPtrWithVal *time.Duration
+		FieldDef        time.Duration `default:"5s"`
+		FieldPtrEnv     *time.Duration
+	}
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		args    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "should handle all fields",
+			args: &Struct{
+				FieldPtrWithVal: &d,
+			},
+			want: &Struct{
+				FieldBlank:      0,
+				FieldEnv:        1500 * time.Millisecond,
+				FieldPtr:        nil,
+				FieldPtrWithVal: &d,
+				FieldDef:        5 * time.Second,
+				FieldPtrEnv:     &d,
+			},
+			envs: map[string]string{
+				"FIELD_ENV":     "1.5s",
+				"FIELD_PTR_ENV": "1m",
+			},
+		},
+		{
+			name: "should error when value has no unit",
+			args: &Struct{},
+			envs: map[string]string{
+				"FIELD_ENV": "5",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			if err := e.Unmarshal(m); (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && tt.want != nil && !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestEcoUnmarshal_convertStrToFieldVal_Time(t *testing.T) {
+	type Struct struct {
+		FieldBlank  time.Time
+		FieldEnv    time.Time
+		FieldLayout time.Time `layout:"2006-01-02"`
+		FieldPtr    *time.Time
+		FieldDef    time.Time `layout:"2006-01-02" default:"2022-01-02"`
+	}
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		args    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "should handle all fields",
+			args: &Struct{},
+			want: &Struct{
+				FieldEnv:    time.Date(2022, 5, 6, 7, 8, 9, 0, time.UTC),
+				FieldLayout: time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC),
+				FieldDef:    time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+			envs: map[string]string{
+				"FIELD_ENV":    "2022-05-06T07:08:09Z",
+				"FIELD_LAYOUT": "2022-05-06",
+			},
+		},
+		{
+			name: "should error when value does not match the layout",
+			args: &Struct{},
+			envs: map[string]string{
+				"FIELD_LAYOUT": "2022-05-06T07:08:09Z",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			if err := e.Unmarshal(m); (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && tt.want != nil && !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestEcoUnmarshal_convertStrToFieldVal_SliceDuration(t *testing.T) {
+	type Struct struct {
+		FieldBlank []time.Duration
+		FieldEnv   []time.Duration
+		FieldDef   []time.Duration `default:"1s,2m"`
+	}
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		args    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "should handle all fields",
+			args: &Struct{},
+			want: &Struct{
+				FieldEnv: []time.Duration{time.Millisecond, time.Hour},
+				FieldDef: []time.Duration{time.Second, 2 * time.Minute},
+			},
+			envs: map[string]string{
+				"FIELD_ENV": "1ms, 1h",
+			},
+		},
+		{
+			name: "should error when an item is invalid",
+			args: &Struct{},
+			envs: map[string]string{
+				"FIELD_ENV": "1ms,foo",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			if err := e.Unmarshal(m); (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && tt.want != nil && !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestEcoUnmarshal_convertStrToFieldVal_TextUnmarshaler(t *testing.T) {
+	warn := SampleLevel(2)
+	info := SampleLevel(1)
+
+	type Struct struct {
+		FieldBlank    SampleLevel
+		FieldEnv      SampleLevel
+		FieldPtr      *SampleLevel
+		FieldDef      SampleLevel `default:"info"`
+		FieldStruct   SampleID
+		FieldSlice    []SampleLevel
+		FieldSlicePtr []*SampleLevel
+	}
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		args    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "should handle all fields",
+			args: &Struct{},
+			want: &Struct{
+				FieldBlank:    0,
+				FieldEnv:      2,
+				FieldPtr:      &warn,
+				FieldDef:      1,
+				FieldStruct:   SampleID{Prefix: "id", Number: 42},
+				FieldSlice:    []SampleLevel{0, 2},
+				FieldSlicePtr: []*SampleLevel{&info, &warn},
+			},
+			envs: map[string]string{
+				"FIELD_ENV":       "warn",
+				"FIELD_PTR":       "WARN",
+				"FIELD_STRUCT":    "id-42",
+				"FIELD_SLICE":     "debug,warn",
+				"FIELD_SLICE_PTR": "info,warn",
+			},
+		},
+		{
+			name: "should error when value is invalid",
+			args: &Struct{},
+			envs: map[string]string{
+				"FIELD_ENV": "trace",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should error when slice item is invalid",
+			args: &Struct{},
+			envs: map[string]string{
+				"FIELD_SLICE": "debug,trace",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			if err := e.Unmarshal(m); (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && tt.want != nil && !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestEcoUnmarshal_convertStrToFieldVal_Decoder(t *testing.T) {
+	d := SampleDecoder("decoded:ptr")
+
+	type Struct struct {
+		FieldBlank SampleDecoder
+		FieldEnv   SampleDecoder
+		FieldPtr   *SampleDecoder
+		FieldSlice []SampleDecoder
+	}
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		args    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "should prefer Decoder over TextUnmarshaler",
+			args: &Struct{},
+			want: &Struct{
+				FieldEnv:   "decoded:env",
+				FieldPtr:   &d,
+				FieldSlice: []SampleDecoder{"decoded:a", "decoded:b"},
+			},
+			envs: map[string]string{
+				"FIELD_ENV":   "env",
+				"FIELD_PTR":   "ptr",
+				"FIELD_SLICE": "a,b",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			if err := e.Unmarshal(m); (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && tt.want != nil && !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_RegisterConverter(t *testing.T) {
+	type VendorID int
+
+	type Struct struct {
+		Addr      netip.Addr
+		Addrs     []netip.Addr
+		URL       *url.URL
+		URLList   []*url.URL
+		Vendor    VendorID
+		VendorPtr *VendorID
+	}
+
+	vendor := VendorID(7)
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		args    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "should convert with registered converters",
+			args: &Struct{},
+			want: &Struct{
+				Addr:      netip.MustParseAddr("10.0.0.1"),
+				Addrs:     []netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("::1")},
+				URL:       &url.URL{Scheme: "https", Host: "example.com"},
+				URLList:   []*url.URL{{Scheme: "http", Host: "a"}, {Scheme: "http", Host: "b"}},
+				Vendor:    7,
+				VendorPtr: &vendor,
+			},
+			envs: map[string]string{
+				"ADDR":       "10.0.0.1",
+				"ADDRS":      "10.0.0.2, ::1",
+				"URL":        "https://example.com",
+				"URL_LIST":   "http://a,http://b",
+				"VENDOR":     "vendor-7",
+				"VENDOR_PTR": "vendor-7",
+			},
+		},
+		{
+			name: "should error when converter fails",
+			args: &Struct{},
+			envs: map[string]string{
+				"ADDR": "10.0.0.256",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should error when converter returns an invalid type",
+			args: &Struct{},
+			envs: map[string]string{
+				"VENDOR": "invalid",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			RegisterTypeWith(e, netip.ParseAddr)
+			e.RegisterConverter(reflect.TypeOf(&url.URL{}), func(value string) (interface{}, error) {
+				return url.Parse(value)
+			})
+			e.RegisterConverter(reflect.TypeOf(VendorID(0)), func(value string) (interface{}, error) {
+				if value == "invalid" {
+					return "invalid", nil
+				}
+				return strconv.Atoi(strings.TrimPrefix(value, "vendor-"))
+			})
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			if err := e.Unmarshal(m); (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && tt.want != nil && !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestEcoUnmarshal_convertStrToFieldVal_Map(t *testing.T) {
+	type Struct struct {
+		FieldBlank    map[string]string
+		FieldEnv      map[string]string
+		FieldInt      map[string]int
+		FieldIntKey   map[int]bool
+		FieldDuration map[string]time.Duration
+		FieldPtr      *map[string]string
+		FieldDef      map[string]string `default:"a:b,c:d"`
+		FieldTagSep   map[string]string `sep:";" kvsep:"="`
+		FieldLevel    map[string]SampleLevel
+	}
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		args    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "should handle all fields",
+			args: &Struct{},
+			want: &Struct{
+				FieldEnv:      map[string]string{"foo": "bar", "baz": "qux"},
+				FieldInt:      map[string]int{"a": 1, "b": -2},
+				FieldIntKey:   map[int]bool{1: true, 2: false},
+				FieldDuration: map[string]time.Duration{"read": time.Second},
+				FieldPtr:      &map[string]string{"k": "v"},
+				FieldDef:      map[string]string{"a": "b", "c": "d"},
+				FieldTagSep:   map[string]string{"url": "http://a:80", "b": "c"},
+				FieldLevel:    map[string]SampleLevel{"api": 2},
+			},
+			envs: map[string]string{
+				"FIELD_ENV":      "foo:bar, baz:qux",
+				"FIELD_INT":      "a:1,b:-2",
+				"FIELD_INT_KEY":  "1:true,2:false",
+				"FIELD_DURATION": "read:1s",
+				"FIELD_PTR":      "k:v",
+				"FIELD_TAG_SEP":  "url=http://a:80;b=c",
+				"FIELD_LEVEL":    "api:warn",
+			},
+		},
+		{
+			name: "should assemble map from prefixed variables",
+			args: &Struct{},
+			want: &Struct{
+				FieldEnv: map[string]string{"team": "core", "tier": "backend"},
+				FieldInt: map[string]int{"max_conns": 10},
+				FieldDef: map[string]string{"a": "b", "c": "d"},
+			},
+			envs: map[string]string{
+				"FIELD_ENV_TEAM":      "core",
+				"FIELD_ENV_TIER":      "backend",
+				"FIELD_INT_MAX_CONNS": "10",
+			},
+		},
+		{
+			name: "should prefer the variable over the prefixed variables",
+			args: &Struct{},
+			want: &Struct{
+				FieldEnv: map[string]string{"foo": "bar"},
+				FieldDef: map[string]string{"a": "b", "c": "d"},
+			},
+			envs: map[string]string{
+				"FIELD_ENV":      "foo:bar",
+				"FIELD_ENV_TEAM": "core",
+			},
+		},
+		{
+			name: "should error when separator is missing",
+			args: &Struct{},
+			envs: map[string]string{
+				"FIELD_ENV": "foo",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should error when value is invalid",
+			args: &Struct{},
+			envs: map[string]string{
+				"FIELD_INT": "a:b",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should error when prefixed value is invalid",
+			args: &Struct{},
+			envs: map[string]string{
+				"FIELD_INT_A": "b",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should error when map type is unsupported",
+			args: &struct {
+				Field map[string][]string
+			}{},
+			envs: map[string]string{
+				"FIELD": "a:b",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			if err := e.Unmarshal(m); (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && tt.want != nil && !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_SetMapSeparators(t *testing.T) {
+	tests := []struct {
+		name        string
+		pairSep     string
+		keyValueSep string
+		envs        map[string]string
+		args        interface{}
+		want        interface{}
+	}{
+		{
+			name:        "should use custom separators",
+			pairSep:     ";",
+			keyValueSep: "=",
+			args: &struct {
+				Labels map[string]string
+			}{},
+			want: &struct {
+				Labels map[string]string
+			}{
+				Labels: map[string]string{"a": "1,2", "b": "3"},
+			},
+			envs: map[string]string{
+				"LABELS": "a=1,2;b=3",
+			},
+		},
+		{
+			name: "should keep default separators when empty",
+			args: &struct {
+				Labels map[string]string
+			}{},
+			want: &struct {
+				Labels map[string]string
+			}{
+				Labels: map[string]string{"a": "1", "b": "2"},
+			},
+			envs: map[string]string{
+				"LABELS": "a:1,b:2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New().SetMapSeparators(tt.pairSep, tt.keyValueSep)
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			if err := e.Unmarshal(m); err != nil {
+				t.Errorf("Eco.Unmarshal() error = %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %v, want %v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_Unmarshal_StructSlice(t *testing.T) {
+	type Upstream struct {
+		Host    string `required:"true"`
+		Port    int    `default:"80"`
+		Options struct {
+			TLS bool
+		}
+	}
+
+	type Struct struct {
+		Upstreams    []Upstream
+		UpstreamPtrs []*Upstream
+		Required     []Upstream `required:"true"`
+	}
+
+	tests := []struct {
+		name    string
+		prefix  string
+		envs    map[string]string
+		args    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "should bind indexed variables",
+			args: &Struct{},
+			want: &Struct{
+				Upstreams: []Upstream{
+					{Host: "a", Port: 8080},
+					{Host: "b", Port: 80, Options: struct{ TLS bool }{TLS: true}},
+				},
+				UpstreamPtrs: []*Upstream{
+					{Host: "c", Port: 80},
+				},
+				Required: []Upstream{
+					{Host: "d", Port: 80},
+				},
+			},
+			envs: map[string]string{
+				"UPSTREAMS_0_HOST":        "a",
+				"UPSTREAMS_0_PORT":        "8080",
+				"UPSTREAMS_1_HOST":        "b",
+				"UPSTREAMS_1_OPTIONS_TLS": "true",
+				"UPSTREAMS_3_HOST":        "not contiguous",
+				"UPSTREAM_PTRS_0_HOST":    "c",
+				"REQUIRED_0_HOST":         "d",
+			},
+		},
+		{
+			name:   "should bind indexed variables with prefix",
+			prefix: "APP",
+			args:   &Struct{},
+			want: &Struct{
+				Upstreams: []Upstream{
+					{Host: "a", Port: 80},
+				},
+				Required: []Upstream{
+					{Host: "d", Port: 80},
+				},
+			},
+			envs: map[string]string{
+				"APP_UPSTREAMS_0_HOST": "a",
+				"APP_REQUIRED_0_HOST":  "d",
+			},
+		},
+		{
+			name: "should keep existing slice when there is no variable",
+			args: &Struct{
+				Upstreams: []Upstream{{Host: "existing"}},
+			},
+			want: &Struct{
+				Upstreams: []Upstream{{Host: "existing"}},
+				Required: []Upstream{
+					{Host: "d", Port: 80},
+				},
+			},
+			envs: map[string]string{
+				"REQUIRED_0_HOST": "d",
+			},
+		},
+		{
+			name: "should error when required slice has no element",
+			args: &Struct{},
+			envs: map[string]string{
+				"UPSTREAMS_0_HOST": "a",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should error when element misses a required field",
+			args: &Struct{},
+			envs: map[string]string{
+				"REQUIRED_0_PORT": "8080",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New().SetPrefix(tt.prefix)
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			if err := e.Unmarshal(m); (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && tt.want != nil && !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_Unmarshal_StructSliceFieldError(t *testing.T) {
+	type Upstream struct {
+		Port int
+	}
+
+	t.Setenv("UPSTREAMS_0_PORT", "80")
+	t.Setenv("UPSTREAMS_1_PORT", "http")
+
+	err := New().Unmarshal(&struct {
+		Upstreams []Upstream
+	}{})
+
+	var ferr *FieldError
+	if !errors.As(err, &ferr) {
+		t.Fatalf("Eco.Unmarshal() error = %v, want *FieldError", err)
+	}
+
+	if ferr.Field != "Upstreams[1].Port" || ferr.Key != "UPSTREAMS_1_PORT" {
+		t.Errorf("FieldError = %v, want Upstreams[1].Port and UPSTREAMS_1_PORT", ferr)
+	}
+}
+
+func TestLoadWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		load    func(e *eco) (interface{}, error)
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "should load struct",
+			load: func(e *eco) (interface{}, error) {
+				return LoadWith[SampleStruct2](e)
+			},
+			envs: map[string]string{
+				"APP_SUB_FOO": "Foo",
+			},
+			want: SampleStruct2{
+				Foo: "Bar",
+				Baz: 100,
+				Sub: SampleStruct3{
+					Foo: "Foo",
+				},
+			},
+		},
+		{
+			name: "should load pointer to struct",
+			load: func(e *eco) (interface{}, error) {
+				return LoadWith[*SampleStruct1](e)
+			},
+			envs: map[string]string{
+				"APP_BAZ": "1",
+			},
+			want: &SampleStruct1{
+				Foo: "Bar",
+				Baz: 1,
+			},
+		},
+		{
+			name: "should error when a field is invalid",
+			load: func(e *eco) (interface{}, error) {
+				return LoadWith[SampleStruct1](e)
+			},
+			envs: map[string]string{
+				"APP_BAZ": "baz",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should error when type is not a struct",
+			load: func(e *eco) (interface{}, error) {
+				return LoadWith[string](e)
+			},
+			wantErr: true,
+		},
+		{
+			name: "should apply the options for the call",
+			load: func(e *eco) (interface{}, error) {
+				return LoadWith[SampleStruct1](e, WithPrefix("CFG"), WithStrict(true))
+			},
+			envs: map[string]string{
+				"APP_BAZ": "1",
+				"CFG_BAZ": "2",
+			},
+			want: SampleStruct1{
+				Foo: "Bar",
+				Baz: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New().SetPrefix("APP")
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			got, err := tt.load(e)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadWith() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadWith() = %#+v, want %#+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMustLoadWith(t *testing.T) {
+	type Struct struct {
+		Foo int `required:"true"`
+		Bar int
+	}
+
+	t.Run("should return loaded value", func(t *testing.T) {
+		t.Setenv("FOO", "1")
+
+		if got := MustLoadWith[Struct](New()); got.Foo != 1 {
+			t.Errorf("MustLoadWith() = %v, want Foo=1", got)
+		}
+	})
+
+	t.Run("should panic with all failed fields", func(t *testing.T) {
+		t.Setenv("BAR", "bar")
+
+		defer func() {
+			r := recover()
+
+			err, ok := r.(error)
+			if !ok {
+				t.Fatalf("MustLoadWith() panic = %v, want error", r)
+			}
+
+			var merr *MultiError
+			if !errors.As(err, &merr) || len(merr.Errors) != 2 {
+				t.Errorf("MustLoadWith() panic = %v, want 2 field errors", err)
+			}
+
+			if !strings.HasPrefix(err.Error(), "cannot load eco.Struct: ") {
+				t.Errorf("MustLoadWith() panic = %v, want the type name", err)
+			}
+		}()
+
+		MustLoadWith[Struct](New())
+	})
+}
+
+func TestEco_SetSources(t *testing.T) {
+	type Struct struct {
+		Foo    string
+		Bar    string
+		Baz    string `default:"baz"`
+		Labels map[string]string
+	}
+
+	local := MapSource(".env.local", map[string]string{
+		"FOO":         "local",
+		"LABELS_TEAM": "core",
+	})
+	base := MapSource(".env", map[string]string{
+		"FOO":         "base",
+		"BAR":         "base",
+		"LABELS_TIER": "backend",
+	})
+
+	tests := []struct {
+		name    string
+		sources []Source
+		envs    map[string]string
+		want    *Struct
+	}{
+		{
+			name:    "should give precedence to the first source",
+			sources: []Source{EnvSource(), local, base},
+			envs: map[string]string{
+				"BAR": "env",
+			},
+			want: &Struct{
+				Foo:    "local",
+				Bar:    "env",
+				Baz:    "baz",
+				Labels: map[string]string{"team": "core", "tier": "backend"},
+			},
+		},
+		{
+			name:    "should ignore nil sources",
+			sources: []Source{nil, base},
+			envs: map[string]string{
+				"BAR": "env",
+			},
+			want: &Struct{
+				Foo:    "base",
+				Bar:    "base",
+				Baz:    "baz",
+				Labels: map[string]string{"tier": "backend"},
+			},
+		},
+		{
+			name: "should keep the environment when no source is given",
+			envs: map[string]string{
+				"FOO": "env",
+			},
+			want: &Struct{
+				Foo: "env",
+				Baz: "baz",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New().SetSources(tt.sources...)
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			got := &Struct{}
+			if err := e.Unmarshal(got); err != nil {
+				t.Fatalf("Eco.Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_Lookup(t *testing.T) {
+	local := MapSource(".env.local", map[string]string{"FOO": "local"})
+	base := MapSource(".env", map[string]string{"FOO": "base", "BAR": "base"})
+
+	t.Setenv("BAZ", "env")
+
+	e := New().SetSources(EnvSource(), local, base)
+
+	tests := []struct {
+		key        string
+		wantValue  string
+		wantSource string
+		wantOk     bool
+	}{
+		{key: "FOO", wantValue: "local", wantSource: ".env.local", wantOk: true},
+		{key: "BAR", wantValue: "base", wantSource: ".env", wantOk: true},
+		{key: "BAZ", wantValue: "env", wantSource: "env", wantOk: true},
+		{key: "QUX", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			v, src, ok := e.Lookup(tt.key)
+			if ok != tt.wantOk || v != tt.wantValue {
+				t.Errorf("Eco.Lookup() = %v, %v, want %v, %v", v, ok, tt.wantValue, tt.wantOk)
+			}
+
+			if ok && src.Name() != tt.wantSource {
+				t.Errorf("Eco.Lookup() source = %v, want %v", src.Name(), tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestEco_SetEmptyMode(t *testing.T) {
+	type Struct struct {
+		Str      string   `default:"default"`
+		Int      int      `default:"1"`
+		Slice    []string `default:"a,b"`
+		Map      map[string]int
+		Ptr      *string `default:"default"`
+		Required string  `required:"true"`
+		Unset    string  `default:"default"`
+	}
+
+	str := "existing"
+
+	tests := []struct {
+		name    string
+		mode    EmptyMode
+		args    *Struct
+		want    *Struct
+		wantErr bool
+	}{
+		{
+			name:    "should treat empty variables as unset",
+			mode:    EmptyAsUnset,
+			args:    &Struct{},
+			wantErr: true,
+		},
+		{
+			name: "should skip default values",
+			mode: EmptySkipsDefault,
+			args: &Struct{
+				Slice: []string{"existing"},
+				Ptr:   &str,
+			},
+			want: &Struct{
+				Slice: []string{"existing"},
+				Ptr:   &str,
+				Unset: "default",
+			},
+		},
+		{
+			name: "should clear values",
+			mode: EmptyClearsValue,
+			args: &Struct{
+				Int:   2,
+				Slice: []string{"existing"},
+				Ptr:   &str,
+			},
+			want: &Struct{
+				Slice: []string{},
+				Map:   map[string]int{},
+				Unset: "default",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New().SetEmptyMode(tt.mode).SetSources(MapSource("test", map[string]string{
+				"STR":      "",
+				"INT":      "",
+				"SLICE":    "",
+				"MAP":      "",
+				"PTR":      "",
+				"REQUIRED": "",
+			}))
+
+			if err := e.Unmarshal(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(tt.args, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", tt.args, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_SetValueLookup(t *testing.T) {
+	type Struct struct {
+		Foo string `default:"default"`
+		Bar string `default:"default"`
+	}
+
+	lookup := func(key string) (string, bool) {
+		if key == "FOO" {
+			return "", true
+		}
+		return "", false
+	}
+
+	tests := []struct {
+		name string
+		mode EmptyMode
+		want *Struct
+	}{
+		{
+			name: "should use default for empty values by default",
+			mode: EmptyAsUnset,
+			want: &Struct{Foo: "default", Bar: "default"},
+		},
+		{
+			name: "should distinguish empty values from unset ones",
+			mode: EmptySkipsDefault,
+			want: &Struct{Foo: "", Bar: "default"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New().SetValueLookup(lookup).SetEmptyMode(tt.mode)
+
+			got := &Struct{}
+			if err := e.Unmarshal(got); err != nil {
+				t.Fatalf("Eco.Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_SetValueGetter_EmptyMode(t *testing.T) {
+	e := New().SetEmptyMode(EmptyClearsValue).SetValueGetter(func(key string) string {
+		return ""
+	})
+
+	got := &struct {
+		Foo string `default:"default"`
+	}{}
+	if err := e.Unmarshal(got); err != nil {
+		t.Fatalf("Eco.Unmarshal() error = %v", err)
+	}
+
+	if got.Foo != "default" {
+		t.Errorf("Eco.Unmarshal() = %v, want default since getters cannot tell empty from unset", got.Foo)
+	}
+}
+
+func TestEco_SetFileIndirection(t *testing.T) {
+	dir := t.TempDir()
+	password := filepath.Join(dir, "password")
+	token := filepath.Join(dir, "token")
+	large := filepath.Join(dir, "large")
+
+	writeFile(t, password, "s3cr3t\n")
+	writeFile(t, token, "t0k3n\r\n")
+	writeFile(t, large, strings.Repeat("a", 11))
+
+	type Struct struct {
+		Password string
+		Token    string `file:"true"`
+		Port     int    `file:"false" default:"80"`
+		Sub      struct {
+			Key string `required:"true"`
+		}
+	}
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		envs       map[string]string
+		want       *Struct
+		wantErr    error
+		wantErrKey string
+	}{
+		{
+			name:    "should read files when enabled globally",
+			enabled: true,
+			envs: map[string]string{
+				"PASSWORD_FILE": password,
+				"TOKEN_FILE":    token,
+				"PORT_FILE":     password,
+				"SUB_KEY_FILE":  token,
+			},
+			want: &Struct{
+				Password: "s3cr3t",
+				Token:    "t0k3n",
+				Port:     80,
+				Sub: struct {
+					Key string `required:"true"`
+				}{Key: "t0k3n"},
+			},
+		},
+		{
+			name: "should read files only for the tagged fields",
+			envs: map[string]string{
+				"PASSWORD_FILE": password,
+				"TOKEN_FILE":    token,
+				"SUB_KEY":       "key",
+			},
+			want: &Struct{
+				Token: "t0k3n",
+				Port:  80,
+				Sub: struct {
+					Key string `required:"true"`
+				}{Key: "key"},
+			},
+		},
+		{
+			name: "should prefer the variable over the file",
+			envs: map[string]string{
+				"TOKEN":      "env",
+				"TOKEN_FILE": token,
+				"SUB_KEY":    "key",
+			},
+			want: &Struct{
+				Token: "env",
+				Port:  80,
+				Sub: struct {
+					Key string `required:"true"`
+				}{Key: "key"},
+			},
+		},
+		{
+			name: "should error when file cannot be read",
+			envs: map[string]string{
+				"TOKEN_FILE": filepath.Join(dir, "missing"),
+				"SUB_KEY":    "key",
+			},
+			wantErr:    os.ErrNotExist,
+			wantErrKey: "TOKEN_FILE",
+		},
+		{
+			name: "should error when file is too large",
+			envs: map[string]string{
+				"TOKEN_FILE": large,
+				"SUB_KEY":    "key",
+			},
+			wantErr:    ErrFileTooLarge,
+			wantErrKey: "TOKEN_FILE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New().SetFileIndirection(tt.enabled).SetFileSizeLimit(10)
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			got := &Struct{}
+			err := e.Unmarshal(got)
+			if tt.wantErr != nil {
+				var ferr *FieldError
+				if !errors.Is(err, tt.wantErr) || !errors.As(err, &ferr) || ferr.Key != tt.wantErrKey {
+					t.Errorf("Eco.Unmarshal() error = %v, want %v for %v", err, tt.wantErr, tt.wantErrKey)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Eco.Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_SetStrict(t *testing.T) {
+	type Struct struct {
+		Port      int
+		Host      string
+		Password  string `file:"true"`
+		Labels    map[string]string
+		Upstreams []struct {
+			Host string
+		}
+		DB struct {
+			Name string
+		}
+	}
+
+	tests := []struct {
+		name    string
+		strict  bool
+		prefix  string
+		vars    map[string]string
+		want    *Struct
+		wantErr string
+	}{
+		{
+			name:   "should accept the variables which are read by the fields",
+			strict: true,
+			prefix: "APP",
+			vars: map[string]string{
+				"APP_PORT":             "8080",
+				"APP_PASSWORD_FILE":    "",
+				"APP_LABELS_TEAM":      "core",
+				"APP_UPSTREAMS_0_HOST": "u0",
+				"APP_DB_NAME":          "app",
+				"OTHER":                "other",
+			},
+			want: &Struct{
+				Port:      8080,
+				Labels:    map[string]string{"team": "core"},
+				Upstreams: []struct{ Host string }{{Host: "u0"}},
+				DB:        struct{ Name string }{Name: "app"},
+			},
+		},
+		{
+			name:   "should report the unknown variables with suggestions",
+			strict: true,
+			prefix: "app_",
+			vars: map[string]string{
+				"APP_PROT":             "8080",
+				"APP_DB_NAEM":          "app",
+				"APP_DB":               "app",
+				"APP_UPSTREAMS_0_HOST": "u0",
+				"APP_UPSTREAMS_2_HOST": "u2",
+				"APP_SOMETHING_ELSE":   "else",
+			},
+			wantErr: "5 errors occurred:" +
+				"\n\t* APP_DB: unknown environment variable" +
+				"\n\t* APP_DB_NAEM: unknown environment variable, did you mean APP_DB_NAME?" +
+				"\n\t* APP_PROT: unknown environment variable, did you mean APP_PORT?" +
+				"\n\t* APP_SOMETHING_ELSE: unknown environment variable" +
+				"\n\t* APP_UPSTREAMS_2_HOST: unknown environment variable, did you mean APP_UPSTREAMS_0_HOST?",
+		},
+		{
+			name:   "should ignore the unknown variables when not strict",
+			prefix: "APP",
+			vars: map[string]string{
+				"APP_PROT": "8080",
+			},
+			want: &Struct{},
+		},
+		{
+			name:   "should ignore the unknown variables without a prefix",
+			strict: true,
+			vars: map[string]string{
+				"PROT": "8080",
+			},
+			want: &Struct{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New().
+				SetStrict(tt.strict).
+				SetPrefix(tt.prefix).
+				SetSources(MapSource("test", tt.vars))
+
+			got := &Struct{}
+			err := e.Unmarshal(got)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Eco.Unmarshal() error = %v, want %v", err, tt.wantErr)
+				}
+
+				if !errors.Is(err, ErrUnknownVariable) {
+					t.Errorf("Eco.Unmarshal() error = %v, want %v", err, ErrUnknownVariable)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Eco.Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type SampleHooksTLS struct {
+	Enabled bool
+	Cert    string
+}
+
+func (s *SampleHooksTLS) Validate() error {
+	if s.Enabled && s.Cert == "" {
+		return errors.New("cert is required when TLS is enabled")
+	}
+	return nil
+}
+
+type SampleHooksUpstream struct {
+	Host string
+	Port int
+}
+
+func (s *SampleHooksUpstream) SetDefaults() {
+	s.Port = 80
+}
+
+type SampleHooksStruct struct {
+	Name      string `default:"eco"`
+	Addresses []string
+	Port      int
+	TLS       *SampleHooksTLS
+	Upstreams []SampleHooksUpstream
+}
+
+func (s *SampleHooksStruct) SetDefaults() {
+	s.Addresses = []string{"127.0.0.1", "::1"}
+	s.Port = 8080
+}
+
+func (s *SampleHooksStruct) Validate() error {
+	if s.Port == 0 {
+		return errors.New("port must not be zero")
+	}
+	return nil
+}
+
+func TestEcoUnmarshal_DefaulterAndValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		envs    map[string]string
+		want    *SampleHooksStruct
+		wantErr string
+	}{
+		{
+			name: "should set the defaults before binding",
+			envs: map[string]string{
+				"PORT":             "9090",
+				"UPSTREAMS_0_HOST": "u0",
+				"UPSTREAMS_1_HOST": "u1",
+				"UPSTREAMS_1_PORT": "8081",
+			},
+			want: &SampleHooksStruct{
+				Name:      "eco",
+				Addresses: []string{"127.0.0.1", "::1"},
+				Port:      9090,
+				TLS:       &SampleHooksTLS{},
+				Upstreams: []SampleHooksUpstream{
+					{Host: "u0", Port: 80},
+					{Host: "u1", Port: 8081},
+				},
+			},
+		},
+		{
+			name:   "should report the error of the root struct with the prefix",
+			prefix: "APP",
+			envs: map[string]string{
+				"APP_PORT": "0",
+			},
+			wantErr: "APP (SampleHooksStruct): port must not be zero",
+		},
+		{
+			name: "should report the error of the nested struct with its prefix",
+			envs: map[string]string{
+				"TLS_ENABLED": "true",
+			},
+			wantErr: "TLS (SampleHooksStruct.TLS): cert is required when TLS is enabled",
+		},
+		{
+			name: "should report the error of the root struct without a prefix",
+			envs: map[string]string{
+				"PORT": "0",
+			},
+			wantErr: "SampleHooksStruct: port must not be zero",
+		},
+		{
+			name: "should not validate the struct if its fields cannot be bound",
+			envs: map[string]string{
+				"PORT":        "0",
+				"TLS_ENABLED": "maybe",
+			},
+			wantErr: `TLS_ENABLED (SampleHooksStruct.TLS.Enabled): strconv.ParseBool: parsing "maybe": invalid syntax`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			got := &SampleHooksStruct{}
+			err := New().SetPrefix(tt.prefix).Unmarshal(got)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Eco.Unmarshal() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Eco.Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type SampleLazyDB struct {
+	Host string `required:"true"`
+	Port int    `default:"5432"`
+}
+
+type SampleLazyStruct struct {
+	Name  string
+	DB    *SampleLazyDB
+	Cache *SampleLazyDB
+}
+
+func TestEco_SetLazyStructs(t *testing.T) {
+	tests := []struct {
+		name    string
+		lazy    bool
+		envs    map[string]string
+		want    *SampleLazyStruct
+		wantErr string
+	}{
+		{
+			name: "should allocate all the struct pointers by default",
+			envs: map[string]string{
+				"DB_HOST":    "db",
+				"CACHE_HOST": "cache",
+			},
+			want: &SampleLazyStruct{
+				DB:    &SampleLazyDB{Host: "db", Port: 5432},
+				Cache: &SampleLazyDB{Host: "cache", Port: 5432},
+			},
+		},
+		{
+			name:    "should fail for the required fields of the unset structs by default",
+			envs:    map[string]string{"DB_HOST": "db"},
+			wantErr: "CACHE_HOST (SampleLazyStruct.Cache.Host): required environment variable is not set",
+		},
+		{
+			name: "should leave the unset struct pointers nil in the lazy mode",
+			lazy: true,
+			envs: map[string]string{
+				"NAME":    "eco",
+				"DB_HOST": "db",
+			},
+			want: &SampleLazyStruct{
+				Name: "eco",
+				DB:   &SampleLazyDB{Host: "db", Port: 5432},
+			},
+		},
+		{
+			name:    "should fail for the required fields of the set structs in the lazy mode",
+			lazy:    true,
+			envs:    map[string]string{"CACHE_PORT": "6379"},
+			wantErr: "CACHE_HOST (SampleLazyStruct.Cache.Host): required environment variable is not set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &SampleLazyStruct{}
+			err := New().
+				SetSources(MapSource("test", tt.envs)).
+				SetLazyStructs(tt.lazy).
+				Unmarshal(got)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Eco.Unmarshal() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Eco.Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type SampleNode struct {
+	Name     string
+	Next     *SampleNode
+	Children []SampleNode
+}
+
+func TestEcoUnmarshal_RecursiveType(t *testing.T) {
+	envs := map[string]string{
+		"NAME":                 "root",
+		"NEXT_NAME":            "next",
+		"NEXT_NEXT_NAME":       "last",
+		"CHILDREN_0_NAME":      "c0",
+		"CHILDREN_1_NAME":      "c1",
+		"CHILDREN_1_NEXT_NAME": "c1-next",
+	}
+
+	got := &SampleNode{}
+	err := New().SetSources(MapSource("test", envs)).SetLazyStructs(true).Unmarshal(got)
+	if err != nil {
+		t.Fatalf("Eco.Unmarshal() error = %v", err)
+	}
+
+	want := &SampleNode{
+		Name: "root",
+		Next: &SampleNode{
+			Name: "next",
+			Next: &SampleNode{Name: "last"},
+		},
+		Children: []SampleNode{
+			{Name: "c0"},
+			{Name: "c1", Next: &SampleNode{Name: "c1-next"}},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, want)
+	}
+
+	// the recursive types cannot be allocated eagerly
+	err = New().SetSources(MapSource("test", envs)).Unmarshal(&SampleNode{})
+	if !errors.Is(err, ErrTypeCycle) {
+		t.Fatalf("Eco.Unmarshal() error = %v, want %v", err, ErrTypeCycle)
+	}
+
+	if want := "NEXT (SampleNode.Next): recursive struct type: eco.SampleNode"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Eco.Unmarshal() error = %v, want %v", err, want)
+	}
+
+	// the cyclic values cannot be bound, even in the lazy mode
+	cyclic := &SampleNode{}
+	cyclic.Next = &SampleNode{Next: cyclic}
+	err = New().SetSources(MapSource("test", envs)).SetLazyStructs(true).Unmarshal(cyclic)
+	if !errors.Is(err, ErrTypeCycle) {
+		t.Fatalf("Eco.Unmarshal() error = %v, want %v", err, ErrTypeCycle)
+	}
+
+	if want := "NEXT_NEXT (SampleNode.Next.Next): recursive struct type: eco.SampleNode"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Eco.Unmarshal() error = %v, want %v", err, want)
+	}
+
+	if cyclic.Name != "root" || cyclic.Next.Name != "next" || cyclic.Next.Next != cyclic {
+		t.Errorf("Eco.Unmarshal() = %#+v, want the cycle to be kept", cyclic)
+	}
+
+	// the shared pointers which do not form a cycle are bound again
+	shared := &SampleNode{Name: "shared"}
+	dag := &SampleNode{Next: shared, Children: []SampleNode{{Next: shared}}}
+	envs = map[string]string{"NAME": "root", "NEXT_NAME": "next"}
+	if err := New().SetSources(MapSource("test", envs)).SetLazyStructs(true).Unmarshal(dag); err != nil {
+		t.Fatalf("Eco.Unmarshal() error = %v", err)
+	}
+
+	if dag.Name != "root" || shared.Name != "next" {
+		t.Errorf("Eco.Unmarshal() = %#+v, want the shared value to be bound", dag)
+	}
+
+	vars, err := New().Describe(SampleNode{})
+	if err != nil {
+		t.Fatalf("Eco.Describe() error = %v", err)
+	}
+
+	if len(vars) != 1 || vars[0].Name != "NAME" {
+		t.Errorf("Eco.Describe() = %+v, want only NAME", vars)
+	}
+}
+
+func TestEco_Unmarshal_NoPrefix(t *testing.T) {
+	type Struct struct {
+		Port     int    `env:"PORT,noprefix"`
+		Hostname string `env:",noprefix,required"`
+		Name     string
+		Sub      struct {
+			Level string
+			Host  string `env:"KUBERNETES_SERVICE_HOST,noprefix"`
+		}
+		Platform struct {
+			Region string
+		} `env:"CLOUD,noprefix"`
+	}
+
+	envs := map[string]string{
+		"PORT":                            "8080",
+		"HOSTNAME":                        "pod-1",
+		"APP_NAME":                        "eco",
+		"APP_SUB_LEVEL":                   "debug",
+		"KUBERNETES_SERVICE_HOST":         "10.0.0.1",
+		"CLOUD_REGION":                    "eu",
+		"APP_PORT":                        "9090",
+		"APP_SUB_KUBERNETES_SERVICE_HOST": "ignored",
+	}
+
+	e := New().SetPrefix("APP").SetSources(MapSource("test", envs))
+
+	got := &Struct{}
+	if err := e.Unmarshal(got); err != nil {
+		t.Fatalf("Eco.Unmarshal() error = %v", err)
+	}
+
+	if got.Port != 8080 || got.Hostname != "pod-1" || got.Name != "eco" ||
+		got.Sub.Level != "debug" || got.Sub.Host != "10.0.0.1" || got.Platform.Region != "eu" {
+		t.Errorf("Eco.Unmarshal() = %+v", got)
+	}
+
+	vars, err := e.Marshal(got)
+	if err != nil {
+		t.Fatalf("Eco.Marshal() error = %v", err)
+	}
+
+	want := map[string]string{
+		"PORT":                    "8080",
+		"HOSTNAME":                "pod-1",
+		"APP_NAME":                "eco",
+		"APP_SUB_LEVEL":           "debug",
+		"KUBERNETES_SERVICE_HOST": "10.0.0.1",
+		"CLOUD_REGION":            "eu",
+	}
+
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("Eco.Marshal() = %v, want %v", vars, want)
+	}
+
+	err = New().SetPrefix("APP").SetSources(MapSource("test", map[string]string{"APP_HOSTNAME": "pod-1"})).Unmarshal(&Struct{})
+	if err == nil || !strings.Contains(err.Error(), "HOSTNAME (Struct.Hostname): required environment variable is not set") {
+		t.Errorf("Eco.Unmarshal() error = %v, want HOSTNAME to be required", err)
+	}
+}
+
+func TestEco_Unmarshal_NoPrefixInStructSlice(t *testing.T) {
+	type Upstream struct {
+		Host string
+		Port int `env:"PORT,noprefix"`
+	}
+
+	type Struct struct {
+		Upstreams []Upstream
+		Backups   []*Upstream
+	}
+
+	envs := map[string]string{
+		"UPSTREAMS_0_HOST": "a",
+		"UPSTREAMS_1_HOST": "b",
+		"PORT":             "80",
+	}
+
+	got := &Struct{}
+	if err := New().SetSources(MapSource("test", envs)).Unmarshal(got); err != nil {
+		t.Fatalf("Eco.Unmarshal() error = %v", err)
+	}
+
+	want := &Struct{
+		Upstreams: []Upstream{{Host: "a", Port: 80}, {Host: "b", Port: 80}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Eco.Unmarshal() = %+v, want %+v", got, want)
+	}
+}
@@ -1,7 +1,104 @@
 package eco
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
 
 var (
 	ErrRequiresNonNilPtr = errors.New("Unmarshal requires non-nil pointer")
+	ErrRequiresStructPtr = errors.New("Unmarshal requires pointer to a struct")
+	ErrRequiresStruct    = errors.New("requires a struct or a non-nil pointer to a struct")
+	ErrRequired          = errors.New("required environment variable is not set")
+	ErrFileTooLarge      = errors.New("file is too large")
+	ErrUnknownVariable   = errors.New("unknown environment variable")
+	ErrInvalidValue      = errors.New("invalid value")
+	ErrConflict          = errors.New("conflicting environment variables are set")
+	ErrReferenceCycle    = errors.New("variable reference cycle")
+	ErrTypeCycle         = errors.New("recursive struct type")
 )
+
+// FieldError describes a failure of binding a single struct field.
+type FieldError struct {
+	// Field is the Go path of the field, e.g. "Config.Sub1.I64".
+	// It is empty for the unknown variables in strict mode.
+	Field string
+	// Key is the resolved environment variable name, or the name
+	// prefix of the struct for the errors of Validator. It is empty
+	// for the root struct without a prefix.
+	Key string
+	// Type is the type of the field.
+	Type reflect.Type
+	// Value is the raw value which could not be bound.
+	Value string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	switch {
+	case e.Field == "":
+		return fmt.Sprintf("%s: %v", e.Key, e.Err)
+	case e.Key == "":
+		return fmt.Sprintf("%s: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("%s (%s): %v", e.Key, e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError collects all field failures of a single Unmarshal call.
+type MultiError struct {
+	Errors []*FieldError
+}
+
+// Error implements the error interface.
+func (m *MultiError) Error() string {
+	if len(m.Errors) == 1 {
+		return m.Errors[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d errors occurred:", len(m.Errors))
+	for _, err := range m.Errors {
+		b.WriteString("\n\t* ")
+		b.WriteString(err.Error())
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the field errors.
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, len(m.Errors))
+	for i, err := range m.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Is reports whether any of the field errors matches the target.
+func (m *MultiError) Is(target error) bool {
+	for _, err := range m.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first field error that matches the target.
+func (m *MultiError) As(target interface{}) bool {
+	for _, err := range m.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
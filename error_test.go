@@ -0,0 +1,106 @@
+package eco
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMultiError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		errs []*FieldError
+		want string
+	}{
+		{
+			name: "should return field error message for single error",
+			errs: []*FieldError{
+				{Field: "Config.Port", Key: "PORT", Err: ErrRequired},
+			},
+			want: "PORT (Config.Port): required environment variable is not set",
+		},
+		{
+			name: "should omit the field path if it is empty",
+			errs: []*FieldError{
+				{Key: "APP_PROT", Err: ErrUnknownVariable},
+			},
+			want: "APP_PROT: unknown environment variable",
+		},
+		{
+			name: "should omit the key if it is empty",
+			errs: []*FieldError{
+				{Field: "Config", Err: ErrInvalidValue},
+			},
+			want: "Config: invalid value",
+		},
+		{
+			name: "should list all field errors",
+			errs: []*FieldError{
+				{Field: "Config.Port", Key: "PORT", Err: ErrRequired},
+				{Field: "Config.Host", Key: "HOST", Err: ErrRequired},
+			},
+			want: "2 errors occurred:" +
+				"\n\t* PORT (Config.Port): required environment variable is not set" +
+				"\n\t* HOST (Config.Host): required environment variable is not set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MultiError{Errors: tt.errs}
+			if got := m.Error(); got != tt.want {
+				t.Errorf("MultiError.Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiError_Is(t *testing.T) {
+	errFoo := errors.New("foo")
+
+	tests := []struct {
+		name   string
+		errs   []*FieldError
+		target error
+		want   bool
+	}{
+		{
+			name: "should match wrapped error",
+			errs: []*FieldError{
+				{Err: errFoo},
+				{Err: ErrRequired},
+			},
+			target: ErrRequired,
+			want:   true,
+		},
+		{
+			name: "should not match unknown error",
+			errs: []*FieldError{
+				{Err: errFoo},
+			},
+			target: ErrRequired,
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MultiError{Errors: tt.errs}
+			if got := errors.Is(m, tt.target); got != tt.want {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiError_As(t *testing.T) {
+	ferr := &FieldError{Field: "Config.Port", Key: "PORT", Err: ErrRequired}
+	m := &MultiError{Errors: []*FieldError{ferr}}
+
+	var got *FieldError
+	if !errors.As(m, &got) {
+		t.Fatalf("errors.As() = false, want true")
+	}
+
+	if !reflect.DeepEqual(got, ferr) {
+		t.Errorf("errors.As() = %v, want %v", got, ferr)
+	}
+}
@@ -0,0 +1,240 @@
+package eco
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// isExpandEnabled reports whether the variable references are expanded
+// for a field, either with the "expand" tag or globally with SetExpand.
+func (e *eco) isExpandEnabled(tags reflect.StructTag) bool {
+	if enabled, err := strconv.ParseBool(tags.Get(e.tagNameExpand)); err == nil {
+		return enabled
+	}
+
+	return e.expand
+}
+
+// expander expands the variable references in the values of the fields of
+// a struct. The references are resolved to the values of the sibling fields,
+// by their resolved names, and then to the variables.
+type expander struct {
+	e *eco
+	// fields are the sibling fields by their resolved names.
+	fields map[string]expandField
+	// stack is the resolved names of the fields which are being expanded.
+	stack []string
+}
+
+// expandField is a sibling field which can be referenced.
+type expandField struct {
+	key    string
+	tags   reflect.StructTag
+	secret bool
+}
+
+// newExpander returns an expander for the fields of the given struct type.
+func (e *eco) newExpander(t reflect.Type, envNameParts []string) *expander {
+	x := &expander{
+		e:      e,
+		fields: map[string]expandField{},
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		typeField := t.Field(i)
+		if !typeField.IsExported() || e.isNestedStruct(typeField.Type) || e.isStructSlice(typeField.Type) {
+			continue
+		}
+
+		_, key, _ := e.getFieldEnvName(typeField, envNameParts)
+		x.fields[key] = expandField{key: key, tags: typeField.Tag, secret: e.isSecret(typeField)}
+	}
+
+	return x
+}
+
+// expandField expands the variable references in the given value of the
+// field with the given resolved name, and reports the reference cycles.
+// It also reports whether any secret sibling field is referenced, directly
+// or through the other fields, so that the result is treated as secret,
+// even if it fails.
+func (x *expander) expandField(key, s string) (string, bool, error) {
+	for i, k := range x.stack {
+		if k == key {
+			cycle := append(append([]string{}, x.stack[i:]...), key)
+			return "", false, fmt.Errorf("%w: %s", ErrReferenceCycle, strings.Join(cycle, " -> "))
+		}
+	}
+
+	x.stack = append(x.stack, key)
+	defer func() { x.stack = x.stack[:len(x.stack)-1] }()
+
+	var secret bool
+	v, err := expandVars(s, func(name string) (string, bool, error) {
+		return x.lookup(name, &secret)
+	})
+
+	return v, secret, err
+}
+
+// lookup returns the value of the referenced sibling field, which is its
+// variable or its default value expanded if enabled, or of the variable.
+// The given secret flag is set if a secret sibling field is referenced.
+func (x *expander) lookup(name string, secret *bool) (string, bool, error) {
+	f, ok := x.fields[name]
+	if !ok {
+		v, _, ok := x.e.Lookup(name)
+		return v, ok, nil
+	}
+
+	if f.secret {
+		*secret = true
+	}
+
+	v, _, ok := x.e.Lookup(f.key)
+	if v == "" {
+		if def, hasDefault := f.tags.Lookup(x.e.tagNameDefault); hasDefault {
+			v, ok = def, true
+		}
+	}
+
+	if v != "" && x.e.isExpandEnabled(f.tags) {
+		expanded, usedSecret, err := x.expandField(f.key, v)
+		*secret = *secret || usedSecret
+		if err != nil {
+			return "", false, err
+		}
+		v = expanded
+	}
+
+	return v, ok, nil
+}
+
+// expandVars expands the variable references in the given value with the
+// shell semantics, which are ${VAR} or $VAR, ${VAR:-fallback} if VAR is unset
+// or empty, ${VAR-fallback} if VAR is unset, ${VAR:?message} and ${VAR?message}
+// to fail likewise. "$$" is a literal "$".
+func expandVars(s string, lookup func(name string) (string, bool, error)) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '$' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+
+		switch next := s[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference %q", s[i:])
+			}
+
+			v, err := expandReference(s[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(v)
+			i = end
+		case isDotenvNameStart(next):
+			j := i + 1
+			for j < len(s) && isVarNameChar(s[j]) {
+				j++
+			}
+
+			v, _, err := lookup(s[i+1 : j])
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(v)
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// expandReference expands the given reference without the braces,
+// e.g. "VAR:-fallback".
+func expandReference(ref string, lookup func(name string) (string, bool, error)) (string, error) {
+	n := 0
+	for n < len(ref) && isVarNameChar(ref[n]) {
+		n++
+	}
+
+	name, op := ref[:n], ref[n:]
+	if name == "" || !isDotenvNameStart(name[0]) {
+		return "", fmt.Errorf("invalid variable reference %q", "${"+ref+"}")
+	}
+
+	v, ok, err := lookup(name)
+	if err != nil || op == "" {
+		return v, err
+	}
+
+	// with the colon, the empty values are treated as unset
+	unset := !ok
+	if strings.HasPrefix(op, ":") {
+		op, unset = op[1:], unset || v == ""
+	}
+
+	switch {
+	case strings.HasPrefix(op, "-"):
+		if unset {
+			return expandVars(op[1:], lookup)
+		}
+		return v, nil
+	case strings.HasPrefix(op, "?"):
+		if !unset {
+			return v, nil
+		}
+
+		msg, err := expandVars(op[1:], lookup)
+		if err != nil {
+			return "", err
+		}
+		if msg == "" {
+			msg = "is not set"
+		}
+		return "", fmt.Errorf("%s: %s", name, msg)
+	}
+
+	return "", fmt.Errorf("invalid variable reference %q", "${"+ref+"}")
+}
+
+// closingBrace returns the index of the brace which closes the reference
+// starting from the given index, or -1 if it is not closed.
+func closingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// isVarNameChar reports whether the given character can be used in
+// a variable name of a reference.
+func isVarNameChar(c byte) bool {
+	return isDotenvNameStart(c) || (c >= '0' && c <= '9')
+}
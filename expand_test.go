@@ -0,0 +1,212 @@
+package eco
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_expandVars(t *testing.T) {
+	vars := map[string]string{
+		"HOST":  "localhost",
+		"PORT":  "8080",
+		"EMPTY": "",
+	}
+	lookup := func(name string) (string, bool, error) {
+		v, ok := vars[name]
+		return v, ok, nil
+	}
+
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr string
+	}{
+		{name: "should keep the value without references", s: "plain", want: "plain"},
+		{name: "should expand the braced references", s: "http://${HOST}:${PORT}", want: "http://localhost:8080"},
+		{name: "should expand the bare references", s: "$HOST:$PORT/x", want: "localhost:8080/x"},
+		{name: "should expand the unset references to empty", s: "a${MISSING}b", want: "ab"},
+		{name: "should keep the escaped dollar", s: "$$HOST costs 5$", want: "$HOST costs 5$"},
+		{name: "should use the fallback if unset or empty", s: "${MISSING:-a}${EMPTY:-b}${HOST:-c}", want: "ablocalhost"},
+		{name: "should use the fallback if unset", s: "${MISSING-a}${EMPTY-b}", want: "a"},
+		{name: "should expand the nested fallback", s: "${MISSING:-${HOST}:${PORT}}", want: "localhost:8080"},
+		{name: "should not fail if set", s: "${HOST:?host is required}", want: "localhost"},
+		{name: "should fail if unset or empty", s: "${EMPTY:?must be set}", wantErr: "EMPTY: must be set"},
+		{name: "should fail with the default message", s: "${MISSING?}", wantErr: "MISSING: is not set"},
+		{name: "should fail for the unterminated reference", s: "${HOST", wantErr: `unterminated variable reference "${HOST"`},
+		{name: "should fail for the invalid reference", s: "${HOST:+x}", wantErr: `invalid variable reference "${HOST:+x}"`},
+		{name: "should fail for the empty reference", s: "${}", wantErr: `invalid variable reference "${}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandVars(tt.s, lookup)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("expandVars() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expandVars() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expandVars() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEcoUnmarshal_Expand(t *testing.T) {
+	type Sub struct {
+		Dir string `default:"${HOME}/sub"`
+	}
+
+	type Struct struct {
+		Host    string `default:"localhost"`
+		Port    int    `default:"8080"`
+		URL     string `default:"http://${APP_HOST}:${APP_PORT}"`
+		Addr    string `default:":${PORT}"`
+		Home    string
+		DataDir string
+		Raw     string `expand:"false"`
+		Sub     Sub
+	}
+
+	// the variables are not shadowed by the siblings with the same
+	// names within the struct, e.g. HOME by APP_HOME
+	t.Setenv("HOME", "/home/eco")
+	t.Setenv("PORT", "3000")
+	t.Setenv("APP_PORT", "9090")
+	t.Setenv("APP_DATA_DIR", "${HOME}/data")
+	t.Setenv("APP_RAW", "${HOME}")
+
+	got := &Struct{}
+	if err := New().SetPrefix("APP").SetExpand(true).Unmarshal(got); err != nil {
+		t.Fatalf("Eco.Unmarshal() error = %v", err)
+	}
+
+	want := &Struct{
+		Host:    "localhost",
+		Port:    9090,
+		URL:     "http://localhost:9090",
+		Addr:    ":3000",
+		DataDir: "/home/eco/data",
+		Raw:     "${HOME}",
+		Sub:     Sub{Dir: "/home/eco/sub"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, want)
+	}
+
+	// expansion is disabled unless it is enabled for the instance or the field
+	type Tagged struct {
+		Dir   string `expand:"true"`
+		Other string
+	}
+
+	t.Setenv("DIR", "${HOME}/dir")
+	t.Setenv("OTHER", "${HOME}")
+
+	tagged := &Tagged{}
+	if err := New().Unmarshal(tagged); err != nil {
+		t.Fatalf("Eco.Unmarshal() error = %v", err)
+	}
+
+	if want := (&Tagged{Dir: "/home/eco/dir", Other: "${HOME}"}); !reflect.DeepEqual(tagged, want) {
+		t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", tagged, want)
+	}
+}
+
+func TestEcoUnmarshal_Expand_Errors(t *testing.T) {
+	type Struct struct {
+		A      string `default:"${B}"`
+		B      string `default:"${C}"`
+		C      string `default:"${A}"`
+		Self   string `default:"x${SELF}"`
+		Token  string `default:"${TOKEN_VALUE:?token is required}"`
+		Secret Secret
+	}
+
+	t.Setenv("SECRET", "hunter2${s3cr3tTail")
+
+	err := New().SetExpand(true).Unmarshal(&Struct{})
+
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 6 {
+		t.Fatalf("Eco.Unmarshal() error = %v, want 6 errors", err)
+	}
+
+	want := []string{
+		"A (Struct.A): variable reference cycle: A -> B -> C -> A",
+		"B (Struct.B): variable reference cycle: B -> C -> A -> B",
+		"C (Struct.C): variable reference cycle: C -> A -> B -> C",
+		"SELF (Struct.Self): variable reference cycle: SELF -> SELF",
+		"TOKEN (Struct.Token): TOKEN_VALUE: token is required",
+		"SECRET (Struct.Secret): invalid value",
+	}
+	for i, fe := range merr.Errors {
+		if fe.Error() != want[i] {
+			t.Errorf("FieldError = %v, want %v", fe, want[i])
+		}
+	}
+
+	if !errors.Is(err, ErrReferenceCycle) {
+		t.Errorf("Eco.Unmarshal() error = %v, want %v", err, ErrReferenceCycle)
+	}
+
+	if strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("Eco.Unmarshal() error = %v, want the secret to be redacted", err)
+	}
+}
+
+func TestEcoExplain_Expand_Secret(t *testing.T) {
+	type Struct struct {
+		Password Secret
+		Host     string `default:"db"`
+		URL      string `default:"postgres://u:${PASSWORD}@${HOST}"`
+		DSN      string `default:"${URL}"`
+		Addr     string `default:"${HOST}:5432"`
+		Port     int    `default:"x${PASSWORD}"`
+	}
+
+	t.Setenv("PASSWORD", "hunter2")
+
+	got := &Struct{}
+	report, err := New().SetExpand(true).Explain(got)
+
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 1 {
+		t.Fatalf("Eco.Explain() error = %v, want 1 error", err)
+	}
+
+	if want := "PORT (Struct.Port): invalid value"; merr.Errors[0].Error() != want {
+		t.Errorf("FieldError = %v, want %v", merr.Errors[0], want)
+	}
+
+	if merr.Errors[0].Value != redactedValue {
+		t.Errorf("FieldError.Value = %v, want %v", merr.Errors[0].Value, redactedValue)
+	}
+
+	want := map[string]string{
+		"Struct.Password": redactedValue,
+		"Struct.Host":     "db",
+		"Struct.URL":      redactedValue,
+		"Struct.DSN":      redactedValue,
+		"Struct.Addr":     "db:5432",
+		"Struct.Port":     redactedValue,
+	}
+	for _, fr := range report.Fields {
+		if fr.Value != want[fr.Field] {
+			t.Errorf("FieldReport.Value of %s = %v, want %v", fr.Field, fr.Value, want[fr.Field])
+		}
+	}
+
+	if got.URL != "postgres://u:hunter2@db" {
+		t.Errorf("Eco.Explain() URL = %v, want the expanded value", got.URL)
+	}
+}
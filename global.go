@@ -1,5 +1,7 @@
 package eco
 
+import "reflect"
+
 var ee *eco
 
 func init() {
@@ -17,6 +19,12 @@ func SetArraySeparator(sep string) *eco {
 	return ee.SetArraySeparator(sep)
 }
 
+// SetMapSeparators sets the separators for map values, e.g. "k1:v1,k2:v2".
+// Default is "," for the pairs and ":" for the keys and values.
+func SetMapSeparators(pairSep, keyValueSep string) *eco {
+	return ee.SetMapSeparators(pairSep, keyValueSep)
+}
+
 // SetEnvNameTransformer sets the function for transforming the environment variable names.
 func SetEnvNameTransformer(transformerFunc envNameTransformerFunc) *eco {
 	return ee.SetEnvNameTransformer(transformerFunc)
@@ -33,7 +41,119 @@ func SetValueGetter(valueGetter envValueGetterFunc) *eco {
 	return ee.SetValueGetter(valueGetter)
 }
 
+// SetValueLookup sets the function for looking up the environment variable
+// values, which reports whether a variable is set like os.LookupEnv.
+func SetValueLookup(valueLookup envValueLookupFunc) *eco {
+	return ee.SetValueLookup(valueLookup)
+}
+
+// SetEmptyMode sets how the explicitly empty variables are handled.
+// Default is EmptyAsUnset.
+func SetEmptyMode(mode EmptyMode) *eco {
+	return ee.SetEmptyMode(mode)
+}
+
+// SetFileIndirection enables or disables reading the values of all the fields
+// from files, e.g. FOO_FILE=/run/secrets/foo for the variable FOO.
+// Default is false.
+func SetFileIndirection(enabled bool) *eco {
+	return ee.SetFileIndirection(enabled)
+}
+
+// SetExpand enables or disables expanding the variable references in the
+// values and the default values of all the fields, e.g. ${HOME}/data.
+// Default is false.
+func SetExpand(enabled bool) *eco {
+	return ee.SetExpand(enabled)
+}
+
+// SetLazyStructs enables or disables leaving the nil pointer to struct fields
+// nil unless a variable of their fields is set. Default is false.
+func SetLazyStructs(enabled bool) *eco {
+	return ee.SetLazyStructs(enabled)
+}
+
+// SetFileSizeLimit sets the maximum size in bytes of the files which are read
+// for the file indirection. Default is 1 MiB.
+func SetFileSizeLimit(limit int64) *eco {
+	return ee.SetFileSizeLimit(limit)
+}
+
+// SetStrict enables or disables the strict mode, which rejects the unknown
+// prefixed variables.
+func SetStrict(strict bool) *eco {
+	return ee.SetStrict(strict)
+}
+
+// SetSources sets the ordered sources for the environment variable values.
+// The first source which has a key supplies its value.
+func SetSources(sources ...Source) *eco {
+	return ee.SetSources(sources...)
+}
+
+// Lookup returns the value of the given environment variable
+// and the source which supplies it.
+func Lookup(key string) (value string, source Source, ok bool) {
+	return ee.Lookup(key)
+}
+
+// RegisterConverter registers a function for converting the environment
+// variable values to the given type.
+func RegisterConverter(t reflect.Type, converter converterFunc) *eco {
+	return ee.RegisterConverter(t, converter)
+}
+
+// RegisterType registers a typed function for converting the environment
+// variable values to the type T.
+func RegisterType[T any](converter func(value string) (T, error)) *eco {
+	return RegisterTypeWith(ee, converter)
+}
+
 // Unmarshal takes a pointer to a struct and unmarshals the environment variables to the struct.
 func Unmarshal(v interface{}) error {
 	return ee.Unmarshal(v)
 }
+
+// Explain is like Unmarshal but returns a report of where the values of the
+// fields come from.
+func Explain(v interface{}) (*Report, error) {
+	return ee.Explain(v)
+}
+
+// Marshal takes a struct, or a pointer to a struct, and returns its values as
+// environment variables.
+func Marshal(v interface{}) (map[string]string, error) {
+	return ee.Marshal(v)
+}
+
+// MarshalEnviron is like Marshal but returns the variables in the "KEY=value"
+// form sorted by their names.
+func MarshalEnviron(v interface{}) ([]string, error) {
+	return ee.MarshalEnviron(v)
+}
+
+// Dump is like MarshalEnviron but redacts the values of the secret fields,
+// and returns the variables as lines, so that the config can be logged.
+func Dump(v interface{}) (string, error) {
+	return ee.Dump(v)
+}
+
+// Describe takes a struct, or a pointer to a struct, and returns the
+// environment variables which would be read by Unmarshal.
+func Describe(v interface{}) (Variables, error) {
+	return ee.Describe(v)
+}
+
+// Load returns a new value of the type T which is populated from the
+// environment variables. T must be a struct or a pointer to a struct.
+// The options configure the global instance for this call only, e.g.
+// eco.Load[Config](eco.WithPrefix("APP")).
+func Load[T any](opts ...Option) (T, error) {
+	return LoadWith[T](ee, opts...)
+}
+
+// MustLoad is like Load but panics if the value cannot be loaded.
+// The panic value is an error which lists all the failed fields.
+func MustLoad[T any](opts ...Option) T {
+	return MustLoadWith[T](ee, opts...)
+}
@@ -3,6 +3,7 @@ package eco
 import (
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -54,6 +55,33 @@ func TestSetArraySeparator(t *testing.T) {
 	}
 }
 
+func TestSetMapSeparators(t *testing.T) {
+	tests := []struct {
+		name        string
+		pairSep     string
+		keyValueSep string
+	}{
+		{
+			name:        "set separators",
+			pairSep:     ";",
+			keyValueSep: "=",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetMapSeparators(tt.pairSep, tt.keyValueSep)
+			if got := ee.mapPairSeparator; got != tt.pairSep {
+				t.Errorf("SetMapSeparators() pair separator = %v, want %v", got, tt.pairSep)
+			}
+			if got := ee.mapKeyValueSeparator; got != tt.keyValueSep {
+				t.Errorf("SetMapSeparators() key value separator = %v, want %v", got, tt.keyValueSep)
+			}
+
+			SetMapSeparators(",", ":")
+		})
+	}
+}
+
 func TestSetEnvNameTransformer(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -224,6 +252,131 @@ func TestSetValueGetter(t *testing.T) {
 	}
 }
 
+func TestRegisterType(t *testing.T) {
+	type Port int
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		args    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "should convert with registered type",
+			args: &struct {
+				Port Port
+			}{},
+			want: &struct {
+				Port Port
+			}{
+				Port: 8080,
+			},
+			envs: map[string]string{
+				"PORT": ":8080",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RegisterType(func(value string) (Port, error) {
+				p, err := strconv.Atoi(strings.TrimPrefix(value, ":"))
+				return Port(p), err
+			})
+			defer delete(ee.converters, reflect.TypeOf(Port(0)))
+
+			m := tt.args
+
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			if err := Unmarshal(m); (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.want != nil && !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %v, want %v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetEmptyMode(t *testing.T) {
+	defer SetEmptyMode(EmptyAsUnset)
+
+	SetEmptyMode(EmptyClearsValue)
+	if got := ee.emptyMode; got != EmptyClearsValue {
+		t.Errorf("SetEmptyMode() = %v, want %v", got, EmptyClearsValue)
+	}
+}
+
+func TestSetValueLookup(t *testing.T) {
+	defer SetSources(EnvSource())
+
+	SetValueLookup(func(key string) (string, bool) {
+		return "lookup", key == "FOO"
+	})
+
+	if v, _, ok := Lookup("FOO"); !ok || v != "lookup" {
+		t.Errorf("Lookup() = %v, %v, want lookup, true", v, ok)
+	}
+}
+
+func TestSetFileIndirection(t *testing.T) {
+	defer SetFileIndirection(false)
+	defer SetFileSizeLimit(defaultFileSizeLimit)
+
+	SetFileIndirection(true).SetFileSizeLimit(10)
+	if !ee.fileIndirection || ee.fileSizeLimit != 10 {
+		t.Errorf("SetFileIndirection() = %v, %v, want true, 10", ee.fileIndirection, ee.fileSizeLimit)
+	}
+
+	SetFileSizeLimit(0)
+	if ee.fileSizeLimit != 10 {
+		t.Errorf("SetFileSizeLimit() = %v, want 10", ee.fileSizeLimit)
+	}
+}
+
+func TestSetStrict(t *testing.T) {
+	defer SetStrict(false)
+
+	SetStrict(true)
+	if !ee.strict {
+		t.Errorf("SetStrict() = %v, want true", ee.strict)
+	}
+}
+
+func TestSetExpand(t *testing.T) {
+	defer SetExpand(false)
+
+	SetExpand(true)
+	if !ee.expand {
+		t.Errorf("SetExpand() = %v, want true", ee.expand)
+	}
+}
+
+func TestSetLazyStructs(t *testing.T) {
+	defer SetLazyStructs(false)
+
+	SetLazyStructs(true)
+	if !ee.lazyStructs {
+		t.Errorf("SetLazyStructs() = %v, want true", ee.lazyStructs)
+	}
+}
+
+func TestSetSources(t *testing.T) {
+	defer SetSources(EnvSource())
+
+	SetSources(MapSource("test", map[string]string{"FOO": "source"}))
+
+	v, src, ok := Lookup("FOO")
+	if !ok || v != "source" || src.Name() != "test" {
+		t.Errorf("Lookup() = %v, %v, %v, want source, test, true", v, src, ok)
+	}
+}
+
 func TestUnmarshal(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -272,3 +425,51 @@ func TestUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad(t *testing.T) {
+	t.Setenv("FOO", "Foo")
+
+	got, err := Load[SampleStruct1]()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := SampleStruct1{Foo: "Foo", Baz: 100}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %v, want %v", got, want)
+	}
+}
+
+func TestLoad_Options(t *testing.T) {
+	t.Setenv("APP_FOO", "env")
+
+	got, err := Load[SampleStruct1](
+		WithPrefix("APP"),
+		WithSources(MapSource("test", map[string]string{"APP_BAZ": "1"}), EnvSource()),
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := SampleStruct1{Foo: "env", Baz: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %v, want %v", got, want)
+	}
+
+	// the options must not change the global instance
+	if ee.getPrefix() != "" || len(ee.sources) != 1 {
+		t.Errorf("Load() changed the global instance, prefix = %q, sources = %d", ee.getPrefix(), len(ee.sources))
+	}
+}
+
+func TestMustLoad(t *testing.T) {
+	t.Setenv("BAZ", "baz")
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("MustLoad() did not panic")
+		}
+	}()
+
+	MustLoad[SampleStruct1]()
+}
@@ -0,0 +1,329 @@
+package eco
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal takes a struct, or a pointer to a struct, and returns its values as
+// environment variables. The names of the variables follow the same rules as
+// Unmarshal, so the returned variables can be unmarshaled into the same struct.
+// Nil pointers, slices and maps are omitted, and the nil elements of the slices
+// of pointers to structs are skipped, renumbering the following elements, since
+// Unmarshal stops at the first missing index. The cyclic values, e.g.
+// n.Next = &n, fail with ErrTypeCycle.
+func (e *eco) Marshal(v interface{}) (map[string]string, error) {
+	return e.marshal(v, false)
+}
+
+// MarshalEnviron is like Marshal but returns the variables in the "KEY=value"
+// form sorted by their names, e.g. for the Env field of exec.Cmd.
+func (e *eco) MarshalEnviron(v interface{}) ([]string, error) {
+	vars, err := e.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return toEnviron(vars), nil
+}
+
+// Dump is like MarshalEnviron but redacts the values of the secret fields,
+// and returns the variables as lines, so that the config can be logged.
+func (e *eco) Dump(v interface{}) (string, error) {
+	vars, err := e.marshal(v, true)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, line := range toEnviron(vars) {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	return b.String(), nil
+}
+
+// marshal returns the values of the given struct as environment variables.
+// If redacted is true, the values of the secret fields are redacted.
+func (e *eco) marshal(v interface{}, redacted bool) (map[string]string, error) {
+	ptrs := map[interface{}]bool{}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, ErrRequiresStruct
+		}
+		ptrs[v] = true
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrRequiresStruct
+	}
+
+	var p []string
+	if prefix := e.getPrefix(); prefix != "" {
+		p = append(p, prefix)
+	}
+
+	out := map[string]string{}
+	if err := e.marshalStructValues(rv, out, ptrs, redacted, rv.Type().Name(), p...); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// marshalStructValues writes the values of the fields of the given struct
+// to the given map, redacting the secret ones if redacted is true. The ptrs
+// are the pointers to the structs which are being written, for detecting
+// the cyclic values. The fieldPath is the Go path of the struct, and it is
+// used for reporting the errors.
+func (e *eco) marshalStructValues(sr reflect.Value, out map[string]string, ptrs map[interface{}]bool, redacted bool, fieldPath string, envNameParts ...string) error {
+	for i := 0; i < sr.Type().NumField(); i++ {
+		field := sr.Field(i)
+		typeField := sr.Type().Field(i)
+
+		// Skip unexported fields
+		if !typeField.IsExported() {
+			continue
+		}
+
+		path := joinFieldPath(fieldPath, typeField.Name)
+		p, envKey, _ := e.getFieldEnvName(typeField, envNameParts)
+
+		// nil pointers, slices and maps are unset variables
+		switch field.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			if field.IsNil() {
+				continue
+			}
+		}
+
+		// if field is a slice of structs, write the indexed variables,
+		// skipping the nil elements without leaving a gap in the indices
+		if e.isStructSlice(typeField.Type) {
+			n := 0
+			for j := 0; j < field.Len(); j++ {
+				elem := field.Index(j)
+				if elem.Kind() == reflect.Ptr && elem.IsNil() {
+					continue
+				}
+
+				ep := append(append([]string{}, p...), strconv.Itoa(n))
+				if err := e.marshalStructPtr(elem, out, ptrs, redacted, fmt.Sprintf("%s[%d]", path, j), ep...); err != nil {
+					return err
+				}
+				n++
+			}
+
+			continue
+		}
+
+		// if field is a struct, write its fields
+		if e.isNestedStruct(typeField.Type) {
+			if err := e.marshalStructPtr(field, out, ptrs, redacted, path, p...); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		val, err := e.convertFieldValToStr(field, typeField.Tag)
+		if err != nil {
+			if e.isSecret(typeField) {
+				err = newRedactedError(err)
+			}
+
+			return &FieldError{
+				Field: path,
+				Key:   envKey,
+				Type:  typeField.Type,
+				Err:   err,
+			}
+		}
+
+		if redacted && e.isSecret(typeField) {
+			val = redact(val)
+		}
+
+		out[envKey] = val
+	}
+
+	return nil
+}
+
+// marshalStructPtr writes the values of the fields of the given struct, or
+// non-nil pointer to a struct, to the given map. It fails with ErrTypeCycle
+// if the pointer is being written already, e.g. n.Next = &n.
+func (e *eco) marshalStructPtr(v reflect.Value, out map[string]string, ptrs map[interface{}]bool, redacted bool, fieldPath string, envNameParts ...string) error {
+	if v.Kind() != reflect.Ptr {
+		return e.marshalStructValues(v, out, ptrs, redacted, fieldPath, envNameParts...)
+	}
+
+	ptr := v.Interface()
+	if ptrs[ptr] {
+		return &FieldError{
+			Field: fieldPath,
+			Key:   e.envNameTransformer(envNameParts, e.envNameSeparator),
+			Type:  v.Type(),
+			Err:   fmt.Errorf("%w: %s", ErrTypeCycle, v.Type().Elem()),
+		}
+	}
+
+	ptrs[ptr] = true
+	defer delete(ptrs, ptr)
+
+	return e.marshalStructValues(v.Elem(), out, ptrs, redacted, fieldPath, envNameParts...)
+}
+
+// convertFieldValToStr converts the given value to its string form, which
+// is the reverse of convertStrToType. The tags of the field are used for
+// the type specific options, such as the layout of the time values.
+func (e *eco) convertFieldValToStr(v reflect.Value, tags reflect.StructTag) (string, error) {
+	// converters are one way, so the types which have a converter are
+	// expected to implement fmt.Stringer if they are not handled below
+	if _, ok := e.converters[v.Type()]; ok {
+		if s, ok := v.Interface().(fmt.Stringer); ok && !isNilPtr(v) {
+			return s.String(), nil
+		}
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		return e.convertFieldValToStr(v.Elem(), tags)
+	}
+
+	// types implementing Encoder take precedence over all the other conversions
+	if enc, ok := addressable(v).Interface().(Encoder); ok {
+		return enc.EncodeEnv()
+	}
+
+	switch v.Type() {
+	case durationType:
+		return v.Interface().(time.Duration).String(), nil
+	case timeType:
+		layout := getTagOrDefault(tags, e.tagNameLayout, defaultTimeLayout)
+		return v.Interface().(time.Time).Format(layout), nil
+	}
+
+	if tm, ok := addressable(v).Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Slice:
+		return e.convertSliceToStr(v, tags)
+	case reflect.Map:
+		return e.convertMapToStr(v, tags)
+	}
+
+	return "", fmt.Errorf("unsupported type: %s", v.Kind())
+}
+
+// convertSliceToStr joins the string forms of the items
+// of the given slice with the slice separator.
+func (e *eco) convertSliceToStr(v reflect.Value, tags reflect.StructTag) (string, error) {
+	sep := getTagOrDefault(tags, e.tagNameSeparator, e.sliceSeparator)
+
+	items := make([]string, v.Len())
+	for i := range items {
+		item, err := e.convertFieldValToStr(v.Index(i), tags)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.Contains(item, sep) {
+			return "", fmt.Errorf("item %q contains the separator %q", item, sep)
+		}
+
+		items[i] = item
+	}
+
+	return strings.Join(items, sep), nil
+}
+
+// convertMapToStr joins the string forms of the keys and the elements
+// of the given map with the map separators, sorted by the keys.
+func (e *eco) convertMapToStr(v reflect.Value, tags reflect.StructTag) (string, error) {
+	pairSep := getTagOrDefault(tags, e.tagNameSeparator, e.mapPairSeparator)
+	kvSep := getTagOrDefault(tags, e.tagNameKeyValueSep, e.mapKeyValueSeparator)
+
+	pairs := make([]string, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k, err := e.convertFieldValToStr(iter.Key(), tags)
+		if err != nil {
+			return "", err
+		}
+
+		val, err := e.convertFieldValToStr(iter.Value(), tags)
+		if err != nil {
+			return "", err
+		}
+
+		if strings.Contains(k, kvSep) || strings.Contains(k, pairSep) || strings.Contains(val, pairSep) {
+			return "", fmt.Errorf("pair %q:%q contains the separators %q or %q", k, val, pairSep, kvSep)
+		}
+
+		pairs = append(pairs, k+kvSep+val)
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, pairSep), nil
+}
+
+// addressable returns a pointer to the given value, so that the methods with
+// pointer receivers can be called. If the value is not addressable, a pointer
+// to a copy of it is returned.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v.Addr()
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr
+}
+
+// isNilPtr reports whether the given value is a nil pointer.
+func isNilPtr(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// toEnviron returns the given variables in the "KEY=value" form sorted by their names.
+func toEnviron(vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	environ := make([]string, len(keys))
+	for i, k := range keys {
+		environ[i] = k + "=" + vars[k]
+	}
+
+	return environ
+}
@@ -0,0 +1,291 @@
+package eco
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type SampleColor string
+
+func (c SampleColor) EncodeEnv() (string, error) {
+	return strings.ToUpper(string(c)), nil
+}
+
+func (c *SampleColor) DecodeEnv(value string) error {
+	*c = SampleColor(strings.ToLower(value))
+	return nil
+}
+
+type SampleMarshalStruct struct {
+	Name     string `default:"app"`
+	Port     int    `env:"HTTP_PORT"`
+	Ratio    float32
+	Debug    bool
+	Timeout  time.Duration
+	Date     time.Time `layout:"2006-01-02"`
+	Hosts    []string
+	Weights  map[string]int
+	Ports    []int `sep:";"`
+	Addr     netip.Addr
+	Color    SampleColor
+	Password *string
+	Skipped  string `env:"-"`
+	DB       struct {
+		Host string
+		Opts *struct {
+			SSL bool
+		}
+	}
+	Upstreams []struct {
+		Host string
+	}
+	unexported string
+}
+
+func TestEco_Marshal(t *testing.T) {
+	password := "s3cr3t"
+
+	tests := []struct {
+		name    string
+		prefix  string
+		args    interface{}
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "should marshal all fields",
+			prefix: "APP",
+			args: &SampleMarshalStruct{
+				Name:     "eco",
+				Port:     8080,
+				Ratio:    0.5,
+				Debug:    true,
+				Timeout:  1500 * time.Millisecond,
+				Date:     time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+				Hosts:    []string{"a", "b"},
+				Weights:  map[string]int{"b": 2, "a": 1},
+				Ports:    []int{80, 443},
+				Addr:     netip.MustParseAddr("10.0.0.1"),
+				Color:    "red",
+				Password: &password,
+				Skipped:  "skipped",
+				Upstreams: []struct {
+					Host string
+				}{{Host: "u0"}, {Host: "u1"}},
+				unexported: "unexported",
+			},
+			want: map[string]string{
+				"APP_NAME":             "eco",
+				"APP_HTTP_PORT":        "8080",
+				"APP_RATIO":            "0.5",
+				"APP_DEBUG":            "true",
+				"APP_TIMEOUT":          "1.5s",
+				"APP_DATE":             "2022-01-02",
+				"APP_HOSTS":            "a,b",
+				"APP_WEIGHTS":          "a:1,b:2",
+				"APP_PORTS":            "80;443",
+				"APP_ADDR":             "10.0.0.1",
+				"APP_COLOR":            "RED",
+				"APP_PASSWORD":         "s3cr3t",
+				"APP":                  "skipped",
+				"APP_DB_HOST":          "",
+				"APP_UPSTREAMS_0_HOST": "u0",
+				"APP_UPSTREAMS_1_HOST": "u1",
+			},
+		},
+		{
+			name: "should marshal struct value",
+			args: SampleStruct2{
+				Foo: "foo",
+				Baz: 1,
+				Sub: SampleStruct3{Foo: "sub"},
+			},
+			want: map[string]string{
+				"FOO":     "foo",
+				"BAZ":     "1",
+				"SUB_FOO": "sub",
+			},
+		},
+		{
+			name: "should error when slice item contains the separator",
+			args: &SampleArrayStruct{
+				Foo: []string{"a,b"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "should error when type is unsupported",
+			args: &struct {
+				Foo complex64
+			}{},
+			wantErr: true,
+		},
+		{
+			name:    "should error when argument is not a struct",
+			args:    &password,
+			wantErr: true,
+		},
+		{
+			name:    "should error when argument is nil",
+			args:    (*SampleStruct1)(nil),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().SetPrefix(tt.prefix).Marshal(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Eco.Marshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eco.Marshal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_Marshal_FieldError(t *testing.T) {
+	_, err := New().Marshal(&SampleArrayStruct{Foo: []string{"a,b"}})
+
+	var ferr *FieldError
+	if !errors.As(err, &ferr) || ferr.Field != "SampleArrayStruct.Foo" || ferr.Key != "FOO" {
+		t.Errorf("Eco.Marshal() error = %v, want *FieldError for FOO", err)
+	}
+}
+
+func TestEco_Marshal_RoundTrip(t *testing.T) {
+	e := New().SetPrefix("APP")
+	RegisterTypeWith(e, netip.ParseAddr)
+	e.RegisterConverter(reflect.TypeOf(&url.URL{}), func(value string) (interface{}, error) {
+		return url.Parse(value)
+	})
+
+	type Struct struct {
+		SampleMarshalStruct
+		URL *url.URL
+	}
+
+	password := "s3cr3t"
+	want := &Struct{
+		SampleMarshalStruct: SampleMarshalStruct{
+			Name:     "eco",
+			Port:     8080,
+			Ratio:    0.25,
+			Debug:    true,
+			Timeout:  time.Minute,
+			Date:     time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+			Hosts:    []string{"a", "b"},
+			Weights:  map[string]int{"a": 1},
+			Ports:    []int{80},
+			Addr:     netip.MustParseAddr("::1"),
+			Color:    "red",
+			Password: &password,
+			Upstreams: []struct {
+				Host string
+			}{{Host: "u0"}},
+		},
+		URL: &url.URL{Scheme: "https", Host: "example.com"},
+	}
+	want.DB.Host = "db"
+	want.DB.Opts = &struct {
+		SSL bool
+	}{SSL: true}
+
+	vars, err := e.Marshal(want)
+	if err != nil {
+		t.Fatalf("Eco.Marshal() error = %v", err)
+	}
+
+	got := &Struct{}
+	if err := e.SetSources(MapSource("marshal", vars)).Unmarshal(got); err != nil {
+		t.Fatalf("Eco.Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Eco.Unmarshal() = %+v, want %+v", got, want)
+	}
+}
+
+func TestEco_Marshal_Cycle(t *testing.T) {
+	n := &SampleNode{Name: "root"}
+	n.Next = &SampleNode{Name: "next", Next: n}
+
+	_, err := New().Marshal(n)
+
+	var ferr *FieldError
+	if !errors.As(err, &ferr) || ferr.Field != "SampleNode.Next.Next" || ferr.Key != "NEXT_NEXT" {
+		t.Fatalf("Eco.Marshal() error = %v, want *FieldError for NEXT_NEXT", err)
+	}
+
+	if !errors.Is(err, ErrTypeCycle) {
+		t.Errorf("Eco.Marshal() error = %v, want %v", err, ErrTypeCycle)
+	}
+
+	if _, err := New().Dump(n); !errors.Is(err, ErrTypeCycle) {
+		t.Errorf("Eco.Dump() error = %v, want %v", err, ErrTypeCycle)
+	}
+
+	// the shared values which do not form a cycle are written again
+	shared := &SampleNode{Name: "shared"}
+	vars, err := New().Marshal(&SampleNode{Next: shared, Children: []SampleNode{{Next: shared}}})
+	if err != nil {
+		t.Fatalf("Eco.Marshal() error = %v", err)
+	}
+
+	if vars["NEXT_NAME"] != "shared" || vars["CHILDREN_0_NEXT_NAME"] != "shared" {
+		t.Errorf("Eco.Marshal() = %v, want the shared value twice", vars)
+	}
+}
+
+func TestEco_Marshal_NilSliceElements(t *testing.T) {
+	type Upstream struct {
+		Host string
+	}
+
+	type Struct struct {
+		Upstreams []*Upstream
+	}
+
+	e := New().SetPrefix("APP")
+	vars, err := e.Marshal(&Struct{Upstreams: []*Upstream{nil, {Host: "a"}, nil, {Host: "b"}}})
+	if err != nil {
+		t.Fatalf("Eco.Marshal() error = %v", err)
+	}
+
+	want := map[string]string{"APP_UPSTREAMS_0_HOST": "a", "APP_UPSTREAMS_1_HOST": "b"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("Eco.Marshal() = %v, want %v", vars, want)
+	}
+
+	got := &Struct{}
+	if err := e.SetSources(MapSource("marshal", vars)).Unmarshal(got); err != nil {
+		t.Fatalf("Eco.Unmarshal() error = %v", err)
+	}
+
+	if len(got.Upstreams) != 2 || got.Upstreams[0].Host != "a" || got.Upstreams[1].Host != "b" {
+		t.Errorf("Eco.Unmarshal() = %+v, want both elements", got)
+	}
+}
+
+func TestEco_MarshalEnviron(t *testing.T) {
+	got, err := New().SetPrefix("APP").MarshalEnviron(&SampleStruct2{
+		Foo: "foo",
+		Baz: 1,
+		Sub: SampleStruct3{Foo: "a=b"},
+	})
+	if err != nil {
+		t.Fatalf("Eco.MarshalEnviron() error = %v", err)
+	}
+
+	want := []string{"APP_BAZ=1", "APP_FOO=foo", "APP_SUB_FOO=a=b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Eco.MarshalEnviron() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package eco
+
+import "reflect"
+
+// Option configures the instance of a single Load call, e.g.
+// eco.Load[Config](eco.WithPrefix("APP")). The options are applied
+// to a copy of the instance, so the instance itself is not changed.
+type Option func(e *eco)
+
+// WithPrefix sets the prefix for the environment variable names.
+func WithPrefix(prefix string) Option {
+	return func(e *eco) { e.SetPrefix(prefix) }
+}
+
+// WithEnvNameSeparator sets the separator for the environment variable names.
+func WithEnvNameSeparator(sep string) Option {
+	return func(e *eco) { e.SetEnvNameSeparator(sep) }
+}
+
+// WithSources sets the ordered sources for the environment variable values.
+func WithSources(sources ...Source) Option {
+	return func(e *eco) { e.SetSources(sources...) }
+}
+
+// WithEmptyMode sets how the explicitly empty variables are handled.
+func WithEmptyMode(mode EmptyMode) Option {
+	return func(e *eco) { e.SetEmptyMode(mode) }
+}
+
+// WithStrict enables or disables the strict mode.
+func WithStrict(strict bool) Option {
+	return func(e *eco) { e.SetStrict(strict) }
+}
+
+// WithFileIndirection enables or disables reading the values from files.
+func WithFileIndirection(enabled bool) Option {
+	return func(e *eco) { e.SetFileIndirection(enabled) }
+}
+
+// WithExpand enables or disables expanding the variable references.
+func WithExpand(enabled bool) Option {
+	return func(e *eco) { e.SetExpand(enabled) }
+}
+
+// WithLazyStructs enables or disables leaving the unset struct pointers nil.
+func WithLazyStructs(enabled bool) Option {
+	return func(e *eco) { e.SetLazyStructs(enabled) }
+}
+
+// with returns the instance itself if there is no option, or a copy
+// of it with the given options applied.
+func (e *eco) with(opts ...Option) *eco {
+	if len(opts) == 0 {
+		return e
+	}
+
+	c := *e
+	c.sources = append([]Source{}, e.sources...)
+	c.converters = make(map[reflect.Type]converterFunc, len(e.converters))
+	for t, f := range e.converters {
+		c.converters[t] = f
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return &c
+}
@@ -0,0 +1,95 @@
+package eco
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Report describes where the values of the fields come from, e.g. to find
+// out why a field has a surprising value. It is returned by Explain.
+type Report struct {
+	// Fields are the reports of the fields, in the order of binding.
+	Fields []FieldReport
+}
+
+// FieldReport describes where the value of a single field comes from.
+type FieldReport struct {
+	// Field is the Go path of the field, e.g. "Config.Sub1.I64".
+	Field string
+	// Key is the environment variable name which is consulted.
+	Key string
+	// Source is the name of the source which supplied the value, e.g. "env"
+	// or ".env". It is empty if the value does not come from a source.
+	Source string
+	// File is the path of the file which the value is read from,
+	// if the value is read with the file indirection.
+	File string
+	// Default reports whether the value of the "default" tag is used.
+	Default bool
+	// Kept reports whether the field keeps its current value, since
+	// neither a variable nor a default value is found for it.
+	Kept bool
+	// Value is the raw value, which is redacted for the secret fields
+	// and for the values expanded from them.
+	Value string
+}
+
+// Origin returns a short description of where the value comes from,
+// e.g. "env", "default", "file /run/secrets/foo (env)" or "kept".
+func (fr FieldReport) Origin() string {
+	switch {
+	case fr.Kept:
+		return "kept"
+	case fr.Default:
+		return "default"
+	case fr.File != "":
+		return fmt.Sprintf("file %s (%s)", fr.File, fr.Source)
+	}
+
+	return fr.Source
+}
+
+// Field returns the report of the field with the given Go path,
+// e.g. "Config.Sub1.I64", or false if there is no such field.
+func (r *Report) Field(path string) (FieldReport, bool) {
+	for _, fr := range r.Fields {
+		if fr.Field == path {
+			return fr, true
+		}
+	}
+
+	return FieldReport{}, false
+}
+
+// String renders the report as a table.
+func (r *Report) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "FIELD\tKEY\tORIGIN\tVALUE")
+	for _, fr := range r.Fields {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%q\n", fr.Field, fr.Key, fr.Origin(), fr.Value)
+	}
+
+	_ = w.Flush()
+	return buf.String()
+}
+
+// formatEntries returns the given map entries as a single value,
+// in the form which can be converted back to the map.
+func (e *eco) formatEntries(entries map[string]string, tags reflect.StructTag) string {
+	pairSep := getTagOrDefault(tags, e.tagNameSeparator, e.mapPairSeparator)
+	kvSep := getTagOrDefault(tags, e.tagNameKeyValueSep, e.mapKeyValueSeparator)
+
+	pairs := make([]string, 0, len(entries))
+	for k, v := range entries {
+		pairs = append(pairs, k+kvSep+v)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, pairSep)
+}
@@ -0,0 +1,123 @@
+package eco
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEco_Explain(t *testing.T) {
+	dir := t.TempDir()
+	token := filepath.Join(dir, "token")
+	writeFile(t, token, "t0k3n\n")
+
+	type Struct struct {
+		Host      string
+		Port      int `default:"8080"`
+		Debug     bool
+		Name      string
+		Password  Secret
+		Token     string `file:"true"`
+		Labels    map[string]string
+		Upstreams []struct {
+			Host string
+		}
+		Sub struct {
+			Level string `default:"info"`
+		}
+	}
+
+	base := MapSource(".env", map[string]string{
+		"HOST":                 "base",
+		"NAME":                 "",
+		"LABELS_TEAM":          "core",
+		"UPSTREAMS_0_HOST":     "u0",
+		"PASSWORD":             "hunter2",
+		"TOKEN_FILE":           token,
+		"SUB_LEVEL":            "debug",
+		"UPSTREAMS_2_HOST":     "u2",
+		"UNRELATED_VARIABLE_1": "x",
+	})
+	local := MapSource(".env.local", map[string]string{
+		"HOST": "local",
+	})
+
+	got := &Struct{Debug: true}
+	report, err := New().
+		SetEmptyMode(EmptySkipsDefault).
+		SetSources(local, base).
+		Explain(got)
+	if err != nil {
+		t.Fatalf("Eco.Explain() error = %v", err)
+	}
+
+	want := []FieldReport{
+		{Field: "Struct.Host", Key: "HOST", Source: ".env.local", Value: "local"},
+		{Field: "Struct.Port", Key: "PORT", Default: true, Value: "8080"},
+		{Field: "Struct.Debug", Key: "DEBUG", Kept: true},
+		{Field: "Struct.Name", Key: "NAME", Source: ".env", Kept: true},
+		{Field: "Struct.Password", Key: "PASSWORD", Source: ".env", Value: "[REDACTED]"},
+		{Field: "Struct.Token", Key: "TOKEN", Source: ".env", File: token, Value: "t0k3n"},
+		{Field: "Struct.Labels", Key: "LABELS", Source: ".env", Value: "team:core"},
+		{Field: "Struct.Upstreams[0].Host", Key: "UPSTREAMS_0_HOST", Source: ".env", Value: "u0"},
+		{Field: "Struct.Sub.Level", Key: "SUB_LEVEL", Source: ".env", Value: "debug"},
+	}
+
+	if !reflect.DeepEqual(report.Fields, want) {
+		t.Errorf("Eco.Explain() = %+v, want %+v", report.Fields, want)
+	}
+
+	if !got.Debug || got.Host != "local" || got.Token != "t0k3n" {
+		t.Errorf("Eco.Explain() did not unmarshal, got %+v", got)
+	}
+
+	if fr, ok := report.Field("Struct.Token"); !ok || fr.Origin() != "file "+token+" (.env)" {
+		t.Errorf("Report.Field() = %+v, %v", fr, ok)
+	}
+}
+
+func TestEco_Explain_Error(t *testing.T) {
+	type Struct struct {
+		Port int `required:"true"`
+		Host string
+	}
+
+	report, err := New().SetSources(MapSource("test", map[string]string{"HOST": "localhost"})).Explain(&Struct{})
+	if !errors.Is(err, ErrRequired) {
+		t.Errorf("Eco.Explain() error = %v, want %v", err, ErrRequired)
+	}
+
+	if report == nil || len(report.Fields) != 2 {
+		t.Fatalf("Eco.Explain() = %+v, want the report of both fields", report)
+	}
+
+	if _, err := New().Explain(Struct{}); !errors.Is(err, ErrRequiresNonNilPtr) {
+		t.Errorf("Eco.Explain() error = %v, want %v", err, ErrRequiresNonNilPtr)
+	}
+
+	if _, err := New().Explain(new(string)); !errors.Is(err, ErrRequiresStructPtr) {
+		t.Errorf("Eco.Explain() error = %v, want %v", err, ErrRequiresStructPtr)
+	}
+}
+
+func TestReport_String(t *testing.T) {
+	report := &Report{
+		Fields: []FieldReport{
+			{Field: "Config.Host", Key: "HOST", Source: "env", Value: "localhost"},
+			{Field: "Config.Port", Key: "PORT", Default: true, Value: "8080"},
+			{Field: "Config.Token", Key: "TOKEN", Source: "env", File: "/run/secrets/token", Value: "[REDACTED]"},
+			{Field: "Config.Debug", Key: "DEBUG", Kept: true},
+		},
+	}
+
+	want := "FIELD         KEY    ORIGIN                         VALUE\n" +
+		"Config.Host   HOST   env                            \"localhost\"\n" +
+		"Config.Port   PORT   default                        \"8080\"\n" +
+		"Config.Token  TOKEN  file /run/secrets/token (env)  \"[REDACTED]\"\n" +
+		"Config.Debug  DEBUG  kept                           \"\"\n"
+
+	if got := report.String(); got != want {
+		t.Errorf("Report.String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package eco
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// checkFieldRules evaluates the conditional rules of the fields of the given
+// struct, which are the "requiredIf", "requiredUnless", "excludes" and
+// "oneOfGroup" tags, after its fields are bound. The rules refer to the
+// sibling fields with their Go names, and a field is set if it is in the
+// given set, which means that it got a value from a variable, including an
+// explicitly empty one unless it is treated as unset, or from its default
+// value, or that any variable of a nested struct is set. The fields which
+// already failed, since the given number of errors, are skipped and are
+// not set.
+func (e *eco) checkFieldRules(sr reflect.Value, st *unmarshalState, errs int, set map[string]bool, fieldPath string, envNameParts []string) {
+	failed := map[string]bool{}
+	for _, fe := range st.errs[errs:] {
+		failed[fe.Field] = true
+	}
+
+	isSet := func(name string) bool {
+		return set[name] && !failed[joinFieldPath(fieldPath, name)]
+	}
+
+	var groups []string
+	members := map[string][]reflect.StructField{}
+
+	for i := 0; i < sr.NumField(); i++ {
+		typeField := sr.Type().Field(i)
+
+		// Skip unexported fields
+		if !typeField.IsExported() {
+			continue
+		}
+
+		path := joinFieldPath(fieldPath, typeField.Name)
+		if failed[path] {
+			continue
+		}
+
+		tags := typeField.Tag
+		_, envKey, _ := e.getFieldEnvName(typeField, envNameParts)
+		fieldSet := isSet(typeField.Name)
+
+		addError := func(err error) {
+			st.addError(&FieldError{
+				Field: path,
+				Key:   envKey,
+				Type:  typeField.Type,
+				Err:   err,
+			})
+		}
+
+		if cond, ok := tags.Lookup(e.tagNameRequiredIf); ok && !fieldSet {
+			match, desc, err := e.matchCondition(sr, cond, isSet, envNameParts)
+			if err != nil {
+				addError(fmt.Errorf("invalid %s tag %q: %w", e.tagNameRequiredIf, cond, err))
+			} else if match {
+				addError(fmt.Errorf("%w when %s", ErrRequired, desc))
+			}
+		}
+
+		if cond, ok := tags.Lookup(e.tagNameRequiredUnless); ok && !fieldSet {
+			match, desc, err := e.matchCondition(sr, cond, isSet, envNameParts)
+			if err != nil {
+				addError(fmt.Errorf("invalid %s tag %q: %w", e.tagNameRequiredUnless, cond, err))
+			} else if !match {
+				addError(fmt.Errorf("%w unless %s", ErrRequired, desc))
+			}
+		}
+
+		if excludes, ok := tags.Lookup(e.tagNameExcludes); ok && fieldSet {
+			for _, name := range strings.Split(excludes, ",") {
+				_, otherField, err := e.siblingField(sr, strings.TrimSpace(name))
+				if err != nil {
+					addError(fmt.Errorf("invalid %s tag %q: %w", e.tagNameExcludes, excludes, err))
+					continue
+				}
+
+				if isSet(otherField.Name) {
+					_, otherKey, _ := e.getFieldEnvName(otherField, envNameParts)
+					addError(fmt.Errorf("%w: %s cannot be set with %s", ErrConflict, envKey, otherKey))
+				}
+			}
+		}
+
+		if group := tags.Get(e.tagNameOneOfGroup); group != "" {
+			if _, ok := members[group]; !ok {
+				groups = append(groups, group)
+			}
+			members[group] = append(members[group], typeField)
+		}
+	}
+
+	for _, group := range groups {
+		e.checkOneOfGroup(st, members[group], isSet, fieldPath, envNameParts)
+	}
+}
+
+// checkOneOfGroup checks whether exactly one of the given fields is set.
+// If none is set, the error is reported for the first field, otherwise
+// it is reported for each field which is set after the first one.
+func (e *eco) checkOneOfGroup(st *unmarshalState, fields []reflect.StructField, isSet func(name string) bool, fieldPath string, envNameParts []string) {
+	keys := make([]string, len(fields))
+	var set []int
+	for i, f := range fields {
+		_, keys[i], _ = e.getFieldEnvName(f, envNameParts)
+		if isSet(f.Name) {
+			set = append(set, i)
+		}
+	}
+
+	if len(set) == 0 {
+		st.addError(&FieldError{
+			Field: joinFieldPath(fieldPath, fields[0].Name),
+			Key:   keys[0],
+			Type:  fields[0].Type,
+			Err:   fmt.Errorf("%w: one of %s must be set", ErrRequired, strings.Join(keys, ", ")),
+		})
+		return
+	}
+
+	for _, i := range set[1:] {
+		st.addError(&FieldError{
+			Field: joinFieldPath(fieldPath, fields[i].Name),
+			Key:   keys[i],
+			Type:  fields[i].Type,
+			Err:   fmt.Errorf("%w: only one of %s can be set", ErrConflict, strings.Join(keys, ", ")),
+		})
+	}
+}
+
+// matchCondition reports whether the given condition holds for the given
+// struct, and returns its description for the errors. The condition is either
+// "Field=value", which holds if the sibling field is set and its string form
+// is the value, or "Field", which holds if the sibling field is set.
+func (e *eco) matchCondition(sr reflect.Value, cond string, isSet func(name string) bool, envNameParts []string) (bool, string, error) {
+	name, want, hasValue := strings.Cut(cond, "=")
+
+	v, typeField, err := e.siblingField(sr, strings.TrimSpace(name))
+	if err != nil {
+		return false, "", err
+	}
+
+	_, key, _ := e.getFieldEnvName(typeField, envNameParts)
+	if !hasValue {
+		return isSet(typeField.Name), key + " is set", nil
+	}
+
+	got, err := e.convertFieldValToStr(v, typeField.Tag)
+	if err != nil {
+		return false, "", err
+	}
+
+	return isSet(typeField.Name) && got == want, key + " is " + want, nil
+}
+
+// siblingField returns the exported field of the given struct with the given name.
+func (e *eco) siblingField(sr reflect.Value, name string) (reflect.Value, reflect.StructField, error) {
+	typeField, ok := sr.Type().FieldByName(name)
+	if !ok || !typeField.IsExported() || len(typeField.Index) != 1 {
+		return reflect.Value{}, reflect.StructField{}, fmt.Errorf("unknown field %s", name)
+	}
+
+	return sr.FieldByIndex(typeField.Index), typeField, nil
+}
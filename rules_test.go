@@ -0,0 +1,200 @@
+package eco
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEcoUnmarshal_FieldRules(t *testing.T) {
+	type DB struct {
+		URL  string `oneOfGroup:"db"`
+		Host string `oneOfGroup:"db"`
+		Port int    `requiredIf:"Host"`
+	}
+
+	type Struct struct {
+		TLSEnabled bool
+		TLSCert    string `requiredIf:"TLSEnabled=true"`
+		Mode       string `default:"local"`
+		Token      string `requiredUnless:"Mode=local"`
+		Socket     string `excludes:"Addr"`
+		Addr       string
+		Insecure   bool `excludes:"TLSEnabled"`
+		DB         DB
+	}
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		want    *Struct
+		wantErr []string
+	}{
+		{
+			name: "should accept the satisfied rules",
+			envs: map[string]string{
+				"TLS_ENABLED": "true",
+				"TLS_CERT":    "cert.pem",
+				"MODE":        "remote",
+				"TOKEN":       "t0k3n",
+				"SOCKET":      "/tmp/app.sock",
+				"DB_HOST":     "localhost",
+				"DB_PORT":     "5432",
+			},
+			want: &Struct{
+				TLSEnabled: true,
+				TLSCert:    "cert.pem",
+				Mode:       "remote",
+				Token:      "t0k3n",
+				Socket:     "/tmp/app.sock",
+				DB:         DB{Host: "localhost", Port: 5432},
+			},
+		},
+		{
+			name: "should not require the fields if the conditions do not hold",
+			envs: map[string]string{
+				"TLS_ENABLED": "false",
+				"ADDR":        ":8080",
+				"DB_URL":      "postgres://localhost",
+			},
+			want: &Struct{
+				Mode: "local",
+				Addr: ":8080",
+				DB:   DB{URL: "postgres://localhost"},
+			},
+		},
+		{
+			name: "should report all the violations",
+			envs: map[string]string{
+				"TLS_ENABLED": "true",
+				"MODE":        "remote",
+				"SOCKET":      "/tmp/app.sock",
+				"ADDR":        ":8080",
+			},
+			wantErr: []string{
+				"DB_URL (Struct.DB.URL): required environment variable is not set: one of DB_URL, DB_HOST must be set",
+				"TLS_CERT (Struct.TLSCert): required environment variable is not set when TLS_ENABLED is true",
+				"TOKEN (Struct.Token): required environment variable is not set unless MODE is local",
+				"SOCKET (Struct.Socket): conflicting environment variables are set: SOCKET cannot be set with ADDR",
+			},
+		},
+		{
+			name: "should treat the explicit zero values as set",
+			envs: map[string]string{
+				"DB_HOST": "localhost",
+				"DB_PORT": "0",
+			},
+			want: &Struct{
+				Mode: "local",
+				DB:   DB{Host: "localhost"},
+			},
+		},
+		{
+			name: "should report the conflicts of the explicit zero values",
+			envs: map[string]string{
+				"TLS_ENABLED": "false",
+				"INSECURE":    "false",
+				"DB_URL":      "postgres://localhost",
+				"DB_HOST":     "localhost",
+			},
+			wantErr: []string{
+				"DB_PORT (Struct.DB.Port): required environment variable is not set when DB_HOST is set",
+				"DB_HOST (Struct.DB.Host): conflicting environment variables are set: only one of DB_URL, DB_HOST can be set",
+				"INSECURE (Struct.Insecure): conflicting environment variables are set: INSECURE cannot be set with TLS_ENABLED",
+			},
+		},
+		{
+			name: "should treat the default values as set",
+			envs: map[string]string{
+				"MODE":   "",
+				"DB_URL": "postgres://localhost",
+			},
+			want: &Struct{
+				Mode: "local",
+				DB:   DB{URL: "postgres://localhost"},
+			},
+		},
+		{
+			name: "should report more than one field of a group",
+			envs: map[string]string{
+				"DB_URL":  "postgres://localhost",
+				"DB_HOST": "localhost",
+			},
+			wantErr: []string{
+				"DB_PORT (Struct.DB.Port): required environment variable is not set when DB_HOST is set",
+				"DB_HOST (Struct.DB.Host): conflicting environment variables are set: only one of DB_URL, DB_HOST can be set",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			got := &Struct{}
+			err := New().Unmarshal(got)
+			if tt.wantErr != nil {
+				var merr *MultiError
+				if !errors.As(err, &merr) {
+					t.Fatalf("Eco.Unmarshal() error = %v, want %v", err, tt.wantErr)
+				}
+
+				var msgs []string
+				for _, fe := range merr.Errors {
+					msgs = append(msgs, fe.Error())
+				}
+
+				if !reflect.DeepEqual(msgs, tt.wantErr) {
+					t.Errorf("Eco.Unmarshal() error = %v, want %v", strings.Join(msgs, "\n"), strings.Join(tt.wantErr, "\n"))
+				}
+
+				if !errors.Is(err, ErrRequired) && !errors.Is(err, ErrConflict) {
+					t.Errorf("Eco.Unmarshal() error = %v, want %v or %v", err, ErrRequired, ErrConflict)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Eco.Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEcoUnmarshal_FieldRules_Errors(t *testing.T) {
+	type Struct struct {
+		Port    int    `required:"true"`
+		Addr    string `requiredIf:"Missing=1"`
+		Socket  string `excludes:"Port"`
+		Cert    string `requiredIf:"Port=443"`
+		Unknown string `requiredUnless:"port"`
+	}
+
+	t.Setenv("PORT", "abc")
+	t.Setenv("SOCKET", "/tmp/app.sock")
+
+	err := New().Unmarshal(&Struct{})
+
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 3 {
+		t.Fatalf("Eco.Unmarshal() error = %v, want 3 errors", err)
+	}
+
+	// the failed port is not set, so it neither conflicts with nor requires the others
+	want := []string{
+		"PORT (Struct.Port): strconv.Atoi",
+		`ADDR (Struct.Addr): invalid requiredIf tag "Missing=1": unknown field Missing`,
+		`UNKNOWN (Struct.Unknown): invalid requiredUnless tag "port": unknown field port`,
+	}
+	for i, fe := range merr.Errors {
+		if !strings.HasPrefix(fe.Error(), want[i]) {
+			t.Errorf("FieldError = %v, want %v", fe, want[i])
+		}
+	}
+}
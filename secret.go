@@ -0,0 +1,86 @@
+package eco
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// redactedValue replaces the values of the secret fields.
+const redactedValue = "[REDACTED]"
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// Secret is a string whose value is masked when it is printed, e.g. with
+// fmt or log, so that it does not leak. The fields of this type are secret
+// fields, just like the fields with the "secret" tag.
+type Secret string
+
+// String implements fmt.Stringer, and returns the masked value.
+func (s Secret) String() string {
+	return redactedValue
+}
+
+// GoString implements fmt.GoStringer, and returns the masked value.
+func (s Secret) GoString() string {
+	return "eco.Secret(" + strconv.Quote(redactedValue) + ")"
+}
+
+// Value returns the actual value of the secret.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// isSecret reports whether the given field is a secret field, either with
+// the "secret" tag or with the Secret type, including slices and pointers.
+func (e *eco) isSecret(typeField reflect.StructField) bool {
+	if secret, err := strconv.ParseBool(typeField.Tag.Get(e.tagNameSecret)); err == nil {
+		return secret
+	}
+
+	t := derefType(typeField.Type)
+	if t.Kind() == reflect.Slice {
+		t = derefType(t.Elem())
+	}
+
+	return t == secretType
+}
+
+// redact returns the redacted form of the given value,
+// which is empty if the value is empty.
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// redactedMessage replaces the messages of the errors of the secret fields.
+const redactedMessage = "invalid value"
+
+// redactedError hides the message of the underlying error, which may quote
+// any part of a secret value, e.g. strconv.NumError or time.ParseError.
+// The underlying error is still available with errors.As.
+type redactedError struct {
+	err error
+}
+
+// newRedactedError returns an error which hides the message of the given
+// error. The validation errors are returned as is, since their messages
+// never contain the value.
+func newRedactedError(err error) error {
+	if _, ok := err.(*ValidationError); ok {
+		return err
+	}
+
+	return &redactedError{err: err}
+}
+
+// Error implements the error interface.
+func (e *redactedError) Error() string {
+	return redactedMessage
+}
+
+// Unwrap returns the underlying error.
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,217 @@
+package eco
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSecret_String(t *testing.T) {
+	s := struct {
+		Password Secret
+		Token    *Secret
+	}{Password: "hunter2"}
+	token := Secret("t0k3n")
+	s.Token = &token
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q"} {
+		got := fmt.Sprintf(format, s) + fmt.Sprintf(format, s.Password) + fmt.Sprintf(format, *s.Token)
+		if strings.Contains(got, "hunter2") || strings.Contains(got, "t0k3n") {
+			t.Errorf("fmt.Sprintf(%q) = %q, want the secrets to be redacted", format, got)
+		}
+	}
+
+	if got := s.Password.Value(); got != "hunter2" {
+		t.Errorf("Secret.Value() = %q, want %q", got, "hunter2")
+	}
+}
+
+func TestEco_isSecret(t *testing.T) {
+	type Struct struct {
+		Plain     string
+		Tagged    string `secret:"true"`
+		Untagged  Secret `secret:"false"`
+		Secret    Secret
+		SecretPtr *Secret
+		Secrets   []Secret
+	}
+
+	want := map[string]bool{
+		"Plain":     false,
+		"Tagged":    true,
+		"Untagged":  false,
+		"Secret":    true,
+		"SecretPtr": true,
+		"Secrets":   true,
+	}
+
+	rt := reflect.TypeOf(Struct{})
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if got := New().isSecret(f); got != want[f.Name] {
+			t.Errorf("isSecret(%s) = %v, want %v", f.Name, got, want[f.Name])
+		}
+	}
+}
+
+func TestRedactedError(t *testing.T) {
+	_, cause := strconv.Atoi("12ab")
+	err := newRedactedError(cause)
+
+	if got, want := err.Error(), "invalid value"; got != want {
+		t.Errorf("redactedError.Error() = %q, want %q", got, want)
+	}
+
+	var nerr *strconv.NumError
+	if !errors.As(err, &nerr) {
+		t.Errorf("errors.As() = false, want the underlying error")
+	}
+
+	verr := &ValidationError{Rule: "min", Param: "8", Msg: "length must be at least 8"}
+	if got := newRedactedError(verr); got != verr {
+		t.Errorf("newRedactedError() = %v, want the validation error as is", got)
+	}
+}
+
+func TestEcoUnmarshal_Secret(t *testing.T) {
+	type Struct struct {
+		Password Secret         `required:"true"`
+		Pin      int            `secret:"true"`
+		Pins     []int          `secret:"true"`
+		Keys     map[string]int `secret:"true"`
+		At       time.Time      `secret:"true"`
+	}
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		want    *Struct
+		wantErr []string
+	}{
+		{
+			name: "should bind the secret values",
+			envs: map[string]string{
+				"PASSWORD": "hunter2",
+				"PIN":      "1234",
+				"PINS":     "1,2",
+				"KEYS_A":   "1",
+			},
+			want: &Struct{
+				Password: "hunter2",
+				Pin:      1234,
+				Pins:     []int{1, 2},
+				Keys:     map[string]int{"a": 1},
+			},
+		},
+		{
+			name: "should redact the secret values in the errors",
+			envs: map[string]string{
+				"PASSWORD": "hunter2",
+				"PIN":      "12ab",
+				"PINS":     "1,x2y",
+				"KEYS_A":   "s3cr3t",
+				"AT":       "2020-01-01Tsecretpart",
+			},
+			wantErr: []string{"12ab", "x2y", "s3cr3t", "secretpart"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			got := &Struct{}
+			err := New().Unmarshal(got)
+			if tt.wantErr != nil {
+				var merr *MultiError
+				if !errors.As(err, &merr) || len(merr.Errors) != 4 {
+					t.Fatalf("Eco.Unmarshal() error = %v, want 4 field errors", err)
+				}
+
+				for _, secret := range tt.wantErr {
+					if strings.Contains(err.Error(), secret) {
+						t.Errorf("Eco.Unmarshal() error = %v, want %q to be redacted", err, secret)
+					}
+				}
+
+				for _, fe := range merr.Errors {
+					if fe.Value != "" && fe.Value != redactedValue {
+						t.Errorf("FieldError.Value = %q, want %q", fe.Value, redactedValue)
+					}
+				}
+
+				var nerr *strconv.NumError
+				if !errors.As(err, &nerr) {
+					t.Errorf("errors.As() = false, want the underlying error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Eco.Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEco_Dump(t *testing.T) {
+	type Struct struct {
+		User     string
+		Password Secret
+		Token    string   `secret:"true"`
+		Empty    string   `secret:"true"`
+		Keys     []Secret `sep:";"`
+	}
+
+	v := &Struct{
+		User:     "admin",
+		Password: "hunter2",
+		Token:    "t0k3n",
+		Keys:     []Secret{"a", "b"},
+	}
+
+	got, err := New().SetPrefix("APP").Dump(v)
+	if err != nil {
+		t.Fatalf("Eco.Dump() error = %v", err)
+	}
+
+	want := "APP_EMPTY=\n" +
+		"APP_KEYS=[REDACTED]\n" +
+		"APP_PASSWORD=[REDACTED]\n" +
+		"APP_TOKEN=[REDACTED]\n" +
+		"APP_USER=admin\n"
+
+	if got != want {
+		t.Errorf("Eco.Dump() = %q, want %q", got, want)
+	}
+
+	// Marshal must still emit the actual values
+	vars, err := New().SetPrefix("APP").Marshal(v)
+	if err != nil {
+		t.Fatalf("Eco.Marshal() error = %v", err)
+	}
+
+	if vars["APP_PASSWORD"] != "hunter2" || vars["APP_TOKEN"] != "t0k3n" || vars["APP_KEYS"] != "a;b" {
+		t.Errorf("Eco.Marshal() = %v, want the actual values", vars)
+	}
+}
+
+func TestEco_Marshal_SecretFieldError(t *testing.T) {
+	type Struct struct {
+		Keys []Secret
+	}
+
+	_, err := New().Marshal(&Struct{Keys: []Secret{"s3,cr3t"}})
+	if err == nil || strings.Contains(err.Error(), "s3,cr3t") {
+		t.Errorf("Eco.Marshal() error = %v, want the secret to be redacted", err)
+	}
+}
@@ -0,0 +1,109 @@
+package eco
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Source provides the values of the environment variables.
+type Source interface {
+	// Name returns the name of the source, e.g. "env" or ".env.local".
+	Name() string
+	// Lookup returns the value of the given key and whether the key exists.
+	Lookup(key string) (string, bool)
+}
+
+// EnumerableSource is a Source which can list its keys. The values of the
+// map fields can be assembled from the prefixed variables of such sources.
+type EnumerableSource interface {
+	Source
+	// Keys returns the names of all the variables of the source.
+	Keys() []string
+}
+
+// EnvSource returns a source for the environment variables of the process.
+func EnvSource() EnumerableSource {
+	return envSource{}
+}
+
+// envSource is the source for the environment variables of the process.
+type envSource struct{}
+
+// Name implements the Source interface.
+func (envSource) Name() string {
+	return "env"
+}
+
+// Lookup implements the Source interface.
+func (envSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// Keys implements the EnumerableSource interface.
+func (envSource) Keys() []string {
+	return environKeys()
+}
+
+// MapSource returns a source for the given values, e.g. for test overrides.
+func MapSource(name string, values map[string]string) EnumerableSource {
+	return &mapSource{name: name, values: values}
+}
+
+// DotenvSource reads the given dotenv files and returns a source for their
+// variables. Unlike DotenvValueGetter, the source does not fall back to
+// the environment of the process, so it can be layered with EnvSource.
+func DotenvSource(paths ...string) (EnumerableSource, error) {
+	vars, err := ReadDotenv(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		paths = []string{defaultDotenvPath}
+	}
+
+	return MapSource(strings.Join(paths, ","), vars), nil
+}
+
+// mapSource is the source for the values of a map.
+type mapSource struct {
+	name   string
+	values map[string]string
+}
+
+// Name implements the Source interface.
+func (s *mapSource) Name() string {
+	return s.name
+}
+
+// Lookup implements the Source interface.
+func (s *mapSource) Lookup(key string) (string, bool) {
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Keys implements the EnumerableSource interface.
+func (s *mapSource) Keys() []string {
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookupSource is the source for a value lookup function.
+type lookupSource struct {
+	lookup envValueLookupFunc
+}
+
+// Name implements the Source interface.
+func (s *lookupSource) Name() string {
+	return "getter"
+}
+
+// Lookup implements the Source interface.
+func (s *lookupSource) Lookup(key string) (string, bool) {
+	return s.lookup(key)
+}
@@ -0,0 +1,82 @@
+package eco
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEnvSource(t *testing.T) {
+	t.Setenv("ECO_SOURCE_FOO", "foo")
+
+	src := EnvSource()
+
+	if got, ok := src.Lookup("ECO_SOURCE_FOO"); !ok || got != "foo" {
+		t.Errorf("EnvSource.Lookup() = %v, %v, want foo, true", got, ok)
+	}
+
+	if _, ok := src.Lookup("ECO_SOURCE_MISSING"); ok {
+		t.Errorf("EnvSource.Lookup() ok = true, want false")
+	}
+
+	found := false
+	for _, k := range src.Keys() {
+		if k == "ECO_SOURCE_FOO" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("EnvSource.Keys() does not contain ECO_SOURCE_FOO")
+	}
+}
+
+func TestMapSource(t *testing.T) {
+	src := MapSource("test", map[string]string{
+		"FOO":   "foo",
+		"EMPTY": "",
+	})
+
+	if got := src.Name(); got != "test" {
+		t.Errorf("MapSource.Name() = %v, want test", got)
+	}
+
+	if got, ok := src.Lookup("EMPTY"); !ok || got != "" {
+		t.Errorf("MapSource.Lookup() = %v, %v, want empty, true", got, ok)
+	}
+
+	if _, ok := src.Lookup("BAR"); ok {
+		t.Errorf("MapSource.Lookup() ok = true, want false")
+	}
+
+	if got, want := src.Keys(), []string{"EMPTY", "FOO"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MapSource.Keys() = %v, want %v", got, want)
+	}
+}
+
+func TestDotenvSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	writeFile(t, path, "FOO=file")
+
+	t.Setenv("BAR", "env")
+
+	src, err := DotenvSource(path)
+	if err != nil {
+		t.Fatalf("DotenvSource() error = %v", err)
+	}
+
+	if got := src.Name(); got != path {
+		t.Errorf("DotenvSource.Name() = %v, want %v", got, path)
+	}
+
+	if got, ok := src.Lookup("FOO"); !ok || got != "file" {
+		t.Errorf("DotenvSource.Lookup() = %v, %v, want file, true", got, ok)
+	}
+
+	if _, ok := src.Lookup("BAR"); ok {
+		t.Errorf("DotenvSource.Lookup() should not fall back to the environment")
+	}
+
+	if _, err := DotenvSource(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("DotenvSource() error = nil, want error")
+	}
+}
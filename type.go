@@ -1,4 +1,71 @@
 package eco
 
+import (
+	"encoding"
+	"reflect"
+	"time"
+)
+
+// defaultTimeLayout is the default layout of the time values.
+const defaultTimeLayout = time.RFC3339
+
+// defaultFileSizeLimit is the default maximum size of the files
+// which are read for the file indirection.
+const defaultFileSizeLimit = 1 << 20
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	decoderType         = reflect.TypeOf((*Decoder)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Decoder is implemented by the types which decode themselves
+// from the value of an environment variable. It takes precedence
+// over encoding.TextUnmarshaler and the built-in conversions.
+type Decoder interface {
+	DecodeEnv(value string) error
+}
+
+// Encoder is implemented by the types which encode themselves to the value
+// of an environment variable. It is the counterpart of Decoder for Marshal,
+// and it takes precedence over encoding.TextMarshaler.
+type Encoder interface {
+	EncodeEnv() (string, error)
+}
+
+// Defaulter is implemented by the structs which set their own default values,
+// e.g. the ones which cannot be expressed with the "default" tag. SetDefaults
+// is called on every struct, root and nested, before its fields are bound.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// Validator is implemented by the structs which validate themselves, e.g.
+// for the rules between their fields. Validate is called on every struct,
+// root and nested, after its fields are bound without any error, and the
+// error is reported with the Go path and the name prefix of the struct.
+type Validator interface {
+	Validate() error
+}
+
 type envNameTransformerFunc func(parts []string, sep string) string
 type envValueGetterFunc func(key string) string
+type envValueLookupFunc func(key string) (string, bool)
+type converterFunc func(value string) (interface{}, error)
+
+// EmptyMode controls how the explicitly empty variables, e.g. "FEATURE_X=",
+// are handled. Variables which are not set at all are never affected.
+type EmptyMode int
+
+const (
+	// EmptyAsUnset treats the empty variables as if they were not set,
+	// so the default values are used.
+	EmptyAsUnset EmptyMode = iota
+	// EmptySkipsDefault does not apply the default values for the empty
+	// variables, so the fields keep their current values.
+	EmptySkipsDefault
+	// EmptyClearsValue sets the fields of the empty variables to their zero
+	// values, e.g. an empty string, and clears the slices and the maps.
+	EmptyClearsValue
+)
@@ -1,6 +1,8 @@
 package eco
 
 import (
+	"os"
+	"reflect"
 	"regexp"
 	"strings"
 )
@@ -20,3 +22,111 @@ func toSnakeCase(in string) (out string) {
 func defaultEnvNameTransformerFunc(parts []string, sep string) string {
 	return strings.ToUpper(strings.Join(parts, sep))
 }
+
+// parseTag splits a tag value into its name and comma separated options.
+func parseTag(tag string) (name string, opts []string) {
+	parts := strings.Split(tag, ",")
+	name = strings.TrimSpace(parts[0])
+	for _, opt := range parts[1:] {
+		if opt = strings.TrimSpace(opt); opt != "" {
+			opts = append(opts, opt)
+		}
+	}
+	return
+}
+
+// hasOption reports whether the given option exists in the options list.
+func hasOption(opts []string, opt string) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// joinFieldPath joins the Go path of a struct and the name of its field.
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// derefType returns the element type of the given type if it is a pointer.
+func derefType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// isMapType reports whether the given type is a map or a pointer to a map.
+func isMapType(t reflect.Type) bool {
+	return derefType(t).Kind() == reflect.Map
+}
+
+// emptyValue returns the zero value of the given type,
+// or an empty one if the type is a slice or a map.
+func emptyValue(t reflect.Type) reflect.Value {
+	switch t.Kind() {
+	case reflect.Slice:
+		return reflect.MakeSlice(t, 0, 0)
+	case reflect.Map:
+		return reflect.MakeMap(t)
+	}
+	return reflect.Zero(t)
+}
+
+// getTagOrDefault returns the value of the given tag,
+// or the default value if the tag is empty.
+func getTagOrDefault(tags reflect.StructTag, name, def string) string {
+	if v := tags.Get(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// environKeys returns the names of the environment variables of the process.
+func environKeys() []string {
+	env := os.Environ()
+	keys := make([]string, 0, len(env))
+	for _, kv := range env {
+		if k, _, ok := strings.Cut(kv, "="); ok && k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// levenshtein returns the edit distance between the given strings,
+// which is the number of the single byte insertions, deletions and
+// substitutions required to change one into the other.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < curr[j] {
+				curr[j] = d
+			}
+			if d := curr[j-1] + 1; d < curr[j] {
+				curr[j] = d
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
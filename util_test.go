@@ -116,3 +116,68 @@ func Test_defaultEnvNameFunction(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		wantName string
+		wantOpts []string
+	}{
+		{
+			name:     "should return empty name for empty tag",
+			tag:      "",
+			wantName: "",
+		},
+		{
+			name:     "should return name without options",
+			tag:      "DB_URL",
+			wantName: "DB_URL",
+		},
+		{
+			name:     "should return name with options",
+			tag:      "DB_URL, required",
+			wantName: "DB_URL",
+			wantOpts: []string{"required"},
+		},
+		{
+			name:     "should return options without name",
+			tag:      ",required",
+			wantName: "",
+			wantOpts: []string{"required"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotOpts := parseTag(tt.tag)
+			if gotName != tt.wantName {
+				t.Errorf("parseTag() name = %v, want %v", gotName, tt.wantName)
+			}
+			if !reflect.DeepEqual(gotOpts, tt.wantOpts) {
+				t.Errorf("parseTag() opts = %v, want %v", gotOpts, tt.wantOpts)
+			}
+		})
+	}
+}
+
+func Test_levenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "", b: "PORT", want: 4},
+		{a: "PORT", b: "PORT", want: 0},
+		{a: "PROT", b: "PORT", want: 2},
+		{a: "PORTS", b: "PORT", want: 1},
+		{a: "HOST", b: "PORT", want: 2},
+		{a: "kitten", b: "sitting", want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := levenshtein(tt.a, tt.b); got != tt.want {
+				t.Errorf("levenshtein() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
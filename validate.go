@@ -0,0 +1,296 @@
+package eco
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError describes a value which violates a validation tag.
+// It matches ErrInvalidValue with errors.Is.
+type ValidationError struct {
+	// Rule is the name of the validation tag, e.g. "min".
+	Rule string
+	// Param is the value of the validation tag, e.g. "1".
+	Param string
+	// Msg describes the violation, e.g. "must be at least 1".
+	Msg string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return e.Msg
+}
+
+// Is reports whether the target is ErrInvalidValue.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidValue
+}
+
+// validate checks the given field value against the validation tags, which
+// are "notempty", "len", "min", "max", "oneof" and "pattern", and returns
+// all the violations. Nil pointers are only checked by "notempty".
+func (e *eco) validate(v reflect.Value, tags reflect.StructTag) []error {
+	var errs []error
+
+	if err := e.validateNotEmpty(v, tags); err != nil {
+		errs = append(errs, err)
+	}
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return errs
+		}
+		v = v.Elem()
+	}
+
+	rules := []struct {
+		name  string
+		check func(v reflect.Value, param string) (string, error)
+	}{
+		{e.tagNameLen, validateLen},
+		{e.tagNameMin, validateMin},
+		{e.tagNameMax, validateMax},
+		{e.tagNameOneOf, e.validateOneOf(tags)},
+		{e.tagNamePattern, e.validatePattern(tags)},
+	}
+
+	for _, rule := range rules {
+		param, ok := tags.Lookup(rule.name)
+		if !ok {
+			continue
+		}
+
+		msg, err := rule.check(v, param)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s tag %q: %w", rule.name, param, err))
+			continue
+		}
+
+		if msg != "" {
+			errs = append(errs, &ValidationError{Rule: rule.name, Param: param, Msg: msg})
+		}
+	}
+
+	return errs
+}
+
+// validateNotEmpty returns the violation of the "notempty" tag by the given
+// value, or nil if the tag is not set or the value is not empty.
+func (e *eco) validateNotEmpty(v reflect.Value, tags reflect.StructTag) error {
+	if notEmpty, _ := strconv.ParseBool(tags.Get(e.tagNameNotEmpty)); notEmpty && isEmptyValue(v) {
+		return &ValidationError{Rule: e.tagNameNotEmpty, Param: "true", Msg: "must not be empty"}
+	}
+	return nil
+}
+
+// validateLen checks whether the length of the given
+// string, slice or map is equal to the given length.
+func validateLen(v reflect.Value, param string) (string, error) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return "", err
+	}
+
+	l, ok := valueLen(v)
+	if !ok {
+		return "", fmt.Errorf("unsupported type: %s", v.Type())
+	}
+
+	if l != n {
+		return fmt.Sprintf("length must be %d", n), nil
+	}
+
+	return "", nil
+}
+
+// validateMin checks whether the given number is at least the given
+// minimum, or the length of the given string, slice or map is.
+func validateMin(v reflect.Value, param string) (string, error) {
+	if l, ok := valueLen(v); ok {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return "", err
+		}
+
+		if l < n {
+			return fmt.Sprintf("length must be at least %d", n), nil
+		}
+		return "", nil
+	}
+
+	c, err := compareNumber(v, param)
+	if err != nil {
+		return "", err
+	}
+
+	if c < 0 {
+		return "must be at least " + param, nil
+	}
+	return "", nil
+}
+
+// validateMax checks whether the given number is at most the given
+// maximum, or the length of the given string, slice or map is.
+func validateMax(v reflect.Value, param string) (string, error) {
+	if l, ok := valueLen(v); ok {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return "", err
+		}
+
+		if l > n {
+			return fmt.Sprintf("length must be at most %d", n), nil
+		}
+		return "", nil
+	}
+
+	c, err := compareNumber(v, param)
+	if err != nil {
+		return "", err
+	}
+
+	if c > 0 {
+		return "must be at most " + param, nil
+	}
+	return "", nil
+}
+
+// validateOneOf returns a check whether the string form of the given value,
+// or of each item of the given slice, is one of the given "|" separated values.
+func (e *eco) validateOneOf(tags reflect.StructTag) func(v reflect.Value, param string) (string, error) {
+	return func(v reflect.Value, param string) (string, error) {
+		allowed := strings.Split(param, "|")
+		return e.validateItems(v, tags, func(s string) string {
+			for _, a := range allowed {
+				if s == a {
+					return ""
+				}
+			}
+			return "must be one of " + strings.Join(allowed, ", ")
+		})
+	}
+}
+
+// validatePattern returns a check whether the string form of the given value,
+// or of each item of the given slice, matches the given regular expression.
+func (e *eco) validatePattern(tags reflect.StructTag) func(v reflect.Value, param string) (string, error) {
+	return func(v reflect.Value, param string) (string, error) {
+		re, err := regexp.Compile(param)
+		if err != nil {
+			return "", err
+		}
+
+		return e.validateItems(v, tags, func(s string) string {
+			if re.MatchString(s) {
+				return ""
+			}
+			return fmt.Sprintf("must match the pattern %q", param)
+		})
+	}
+}
+
+// validateItems checks the string form of the given value, or of each item
+// of the given slice, with the given check, and returns the first violation.
+func (e *eco) validateItems(v reflect.Value, tags reflect.StructTag, check func(s string) string) (string, error) {
+	items := []reflect.Value{v}
+	if v.Kind() == reflect.Slice {
+		items = items[:0]
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i))
+		}
+	}
+
+	for _, item := range items {
+		s, err := e.convertFieldValToStr(item, tags)
+		if err != nil {
+			return "", err
+		}
+
+		if msg := check(s); msg != "" {
+			return msg, nil
+		}
+	}
+
+	return "", nil
+}
+
+// valueLen returns the length of the given string, in characters,
+// slice or map, and reports false if the value has no length.
+func valueLen(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Map:
+		return v.Len(), true
+	}
+
+	return 0, false
+}
+
+// compareNumber compares the given number with the given parameter, which is
+// parsed as the same kind of number, or as a duration for time.Duration. It
+// returns -1, 0 or 1 if the number is less than, equal to or greater than it.
+func compareNumber(v reflect.Value, param string) (int, error) {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(param)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Int(), int64(d)), nil
+	case v.CanInt():
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Int(), n), nil
+	case v.CanUint():
+		n, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Uint(), n), nil
+	case v.CanFloat():
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Float(), n), nil
+	}
+
+	return 0, fmt.Errorf("unsupported type: %s", v.Type())
+}
+
+// compare returns -1, 0 or 1 if a is less than, equal to or greater than b.
+func compare[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// isEmptyValue reports whether the given value is empty,
+// which is a nil pointer, an empty string, slice or map,
+// or the zero value of the other types.
+func isEmptyValue(v reflect.Value) bool {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+
+	if l, ok := valueLen(v); ok {
+		return l == 0
+	}
+
+	return v.IsZero()
+}
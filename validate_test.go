@@ -0,0 +1,208 @@
+package eco
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEcoUnmarshal_Validate(t *testing.T) {
+	type Struct struct {
+		Port     int           `min:"1" max:"65535" default:"8080"`
+		Ratio    float64       `min:"0" max:"1"`
+		Workers  uint          `max:"8"`
+		Timeout  time.Duration `min:"1s" max:"1m"`
+		Level    string        `oneof:"debug|info|warn" default:"info"`
+		Levels   []string      `oneof:"debug|info|warn"`
+		Code     string        `len:"3"`
+		Hosts    []string      `min:"1" max:"2"`
+		Name     string        `pattern:"^[a-z]+$"`
+		ID       int           `notempty:"true"`
+		Region   *string       `oneof:"eu|us"`
+		Password Secret        `min:"8" pattern:"[0-9]"`
+	}
+
+	tests := []struct {
+		name    string
+		envs    map[string]string
+		want    *Struct
+		wantErr []string
+	}{
+		{
+			name: "should accept the valid values",
+			envs: map[string]string{
+				"RATIO":    "0.5",
+				"WORKERS":  "8",
+				"TIMEOUT":  "30s",
+				"LEVELS":   "debug,warn",
+				"CODE":     "çöğ",
+				"HOSTS":    "a,b",
+				"NAME":     "eco",
+				"ID":       "1",
+				"PASSWORD": "hunter22",
+			},
+			want: &Struct{
+				Port:     8080,
+				Ratio:    0.5,
+				Workers:  8,
+				Timeout:  30 * time.Second,
+				Level:    "info",
+				Levels:   []string{"debug", "warn"},
+				Code:     "çöğ",
+				Hosts:    []string{"a", "b"},
+				Name:     "eco",
+				ID:       1,
+				Password: "hunter22",
+			},
+		},
+		{
+			name: "should not validate the unset fields except notempty",
+			envs: map[string]string{"ID": "1"},
+			want: &Struct{Port: 8080, Level: "info", ID: 1},
+		},
+		{
+			name: "should report all the violations",
+			envs: map[string]string{
+				"PORT":     "0",
+				"RATIO":    "1.5",
+				"WORKERS":  "9",
+				"TIMEOUT":  "500ms",
+				"LEVEL":    "trace",
+				"LEVELS":   "debug,trace",
+				"CODE":     "ab",
+				"HOSTS":    "a,b,c",
+				"NAME":     "Eco",
+				"ID":       "0",
+				"REGION":   "asia",
+				"PASSWORD": "short",
+			},
+			wantErr: []string{
+				"PORT (Struct.Port): must be at least 1",
+				"RATIO (Struct.Ratio): must be at most 1",
+				"WORKERS (Struct.Workers): must be at most 8",
+				"TIMEOUT (Struct.Timeout): must be at least 1s",
+				"LEVEL (Struct.Level): must be one of debug, info, warn",
+				"LEVELS (Struct.Levels): must be one of debug, info, warn",
+				"CODE (Struct.Code): length must be 3",
+				"HOSTS (Struct.Hosts): length must be at most 2",
+				`NAME (Struct.Name): must match the pattern "^[a-z]+$"`,
+				"ID (Struct.ID): must not be empty",
+				"REGION (Struct.Region): must be one of eu, us",
+				"PASSWORD (Struct.Password): length must be at least 8",
+				`PASSWORD (Struct.Password): must match the pattern "[0-9]"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envs {
+				t.Setenv(k, v)
+			}
+
+			got := &Struct{}
+			err := New().Unmarshal(got)
+			if tt.wantErr != nil {
+				var merr *MultiError
+				if !errors.As(err, &merr) {
+					t.Fatalf("Eco.Unmarshal() error = %v, want %v", err, tt.wantErr)
+				}
+
+				var msgs []string
+				for _, fe := range merr.Errors {
+					msgs = append(msgs, fe.Error())
+				}
+
+				if !reflect.DeepEqual(msgs, tt.wantErr) {
+					t.Errorf("Eco.Unmarshal() error = %v, want %v", strings.Join(msgs, "\n"), strings.Join(tt.wantErr, "\n"))
+				}
+
+				var verr *ValidationError
+				if !errors.Is(err, ErrInvalidValue) || !errors.As(err, &verr) || verr.Rule != "min" || verr.Param != "1" {
+					t.Errorf("Eco.Unmarshal() error = %v, want %v", err, ErrInvalidValue)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Eco.Unmarshal() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eco.Unmarshal() = %#+v, want %#+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEcoUnmarshal_Validate_InvalidTag(t *testing.T) {
+	type Struct struct {
+		Port    int       `min:"one"`
+		Name    string    `pattern:"["`
+		Created time.Time `max:"1"`
+		Count   int       `len:"1"`
+	}
+
+	t.Setenv("PORT", "1")
+	t.Setenv("NAME", "eco")
+	t.Setenv("CREATED", "2022-01-02T00:00:00Z")
+	t.Setenv("COUNT", "1")
+
+	err := New().Unmarshal(&Struct{})
+
+	var merr *MultiError
+	if !errors.As(err, &merr) || len(merr.Errors) != 4 {
+		t.Fatalf("Eco.Unmarshal() error = %v, want 4 errors", err)
+	}
+
+	for _, fe := range merr.Errors {
+		if !strings.Contains(fe.Error(), "invalid") || errors.Is(fe, ErrInvalidValue) {
+			t.Errorf("FieldError = %v, want an invalid tag error", fe)
+		}
+	}
+}
+
+func TestEcoUnmarshal_Validate_EmptyClearsValue(t *testing.T) {
+	type Struct struct {
+		Hosts []string `notempty:"true"`
+	}
+
+	t.Setenv("HOSTS", "")
+
+	err := New().SetEmptyMode(EmptyClearsValue).Unmarshal(&Struct{Hosts: []string{"a"}})
+	if !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Eco.Unmarshal() error = %v, want %v", err, ErrInvalidValue)
+	}
+}
+
+func TestEcoUnmarshal_Validate_NotEmptyUnset(t *testing.T) {
+	type Struct struct {
+		Name  string   `notempty:"true"`
+		Hosts []string `notempty:"true"`
+		Port  *int     `notempty:"true"`
+		Kept  string   `notempty:"true"`
+	}
+
+	for _, mode := range []EmptyMode{EmptyAsUnset, EmptySkipsDefault, EmptyClearsValue} {
+		t.Run(fmt.Sprint(mode), func(t *testing.T) {
+			e := New().
+				SetEmptyMode(mode).
+				SetSources(MapSource("test", map[string]string{"NAME": ""}))
+
+			err := e.Unmarshal(&Struct{Kept: "kept"})
+
+			var merr *MultiError
+			if !errors.As(err, &merr) || len(merr.Errors) != 3 {
+				t.Fatalf("Eco.Unmarshal() error = %v, want 3 errors", err)
+			}
+
+			for i, key := range []string{"NAME", "HOSTS", "PORT"} {
+				if fe := merr.Errors[i]; fe.Key != key || !errors.Is(fe, ErrInvalidValue) {
+					t.Errorf("FieldError = %v, want %s: must not be empty", fe, key)
+				}
+			}
+		})
+	}
+}